@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+)
+
+// manifestFiles returns file, or the JSON files under it if it's a
+// directory, sorted.
+func manifestFiles(file string) ([]string, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{file}, nil
+	}
+
+	var files []string
+
+	err = filepath.Walk(file, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && strings.HasSuffix(path, ".json") {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+
+	sort.Strings(files)
+
+	return files, err
+}
+
+// loadManifests decodes the objects described by file, a JSON file or a
+// directory of them.
+func loadManifests(file string) ([]*resource, []api.Object, error) {
+	files, err := manifestFiles(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resources := make([]*resource, 0, len(files))
+	objs := make([]api.Object, 0, len(files))
+
+	for _, path := range files {
+		body, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		res, obj, err := decodeManifest(body, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		resources = append(resources, res)
+		objs = append(objs, obj)
+	}
+
+	return resources, objs, nil
+}
+
+var applyFlags struct {
+	file   string
+	dryRun bool
+	prune  bool
+}
+
+// actionSuffix is printed after the resources, e.g. accounts/ID updated.
+var actionSuffix = map[pkg.ApplyAction]string{
+	pkg.ApplyCreate:    "created",
+	pkg.ApplyUpdate:    "updated",
+	pkg.ApplyDelete:    "deleted",
+	pkg.ApplyUnchanged: "unchanged",
+}
+
+var applyCommand = &command{
+	name:    "apply",
+	usage:   "apply -f FILE|DIR",
+	summary: "Create or update resources to match JSON manifests, showing the fields changed. Fields left out or set to \"\" are left as they are.",
+	flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&applyFlags.file, "f", "", "JSON manifest, or directory of manifests")
+		fs.BoolVar(&applyFlags.dryRun, "dry-run", false, "only show what would change")
+		fs.BoolVar(&applyFlags.prune, "prune", false, "delete the resources of the applied types missing from the manifests")
+	},
+	run: func(ctx context.Context, c *cli, client pkg.Client, args []string) error {
+		if applyFlags.file == "" || len(args) != 0 {
+			return errUsage
+		}
+
+		resources, objs, err := loadManifests(applyFlags.file)
+		if err != nil {
+			return err
+		}
+
+		names := map[string]string{}
+		for i, res := range resources {
+			names[api.Schema.TypeName(objs[i])] = res.name
+		}
+
+		results, applyErr := client.Apply(ctx, objs, &pkg.ApplyOptions{DryRun: applyFlags.dryRun, Prune: applyFlags.prune})
+		if applyErr != nil && !errors.Is(applyErr, pkg.ErrApplyFailed) {
+			return applyErr
+		}
+
+		suffix := ""
+		if applyFlags.dryRun {
+			suffix = " (dry run)"
+		}
+
+		for _, result := range results {
+			name := names[api.Schema.TypeName(result.Object)]
+
+			if result.Err != nil {
+				fmt.Fprintf(c.stdout, "%s/%s failed: %s\n", name, result.Object.GetID(), result.Err)
+				continue
+			}
+
+			fmt.Fprintf(c.stdout, "%s/%s %s%s\n", name, result.Object.GetID(), actionSuffix[result.Action], suffix)
+
+			if result.Action == pkg.ApplyUpdate {
+				for _, diff := range result.Diff {
+					fmt.Fprintf(c.stdout, "  %s\n", diff)
+				}
+			}
+		}
+
+		return applyErr
+	},
+}
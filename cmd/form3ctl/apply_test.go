@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+var _ = Describe("apply", func() {
+	const extraID = "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5"
+
+	var (
+		server *fakeapi.Server
+		dir    string
+		stdout *bytes.Buffer
+		stderr *bytes.Buffer
+	)
+
+	form3ctl := func(args ...string) int {
+		stdout.Reset()
+		stderr.Reset()
+
+		return run(context.TODO(), append(args, "--host", server.URL), nil, stdout, stderr)
+	}
+
+	writeManifest := func(name string, obj api.Object) {
+		body, err := json.Marshal(api.WrapObject(obj))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(dir, name), body, 0o600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		server = fakeapi.NewServer()
+		Expect(server.Seed(newAccount(accountID), newAccount(extraID))).To(Succeed())
+
+		var err error
+		dir, err = ioutil.TempDir("", "form3ctl")
+		Expect(err).NotTo(HaveOccurred())
+
+		changed := newAccount(accountID)
+		changed.Attributes.BankID = "400302"
+		writeManifest("changed.json", changed)
+		writeManifest("missing.json", newAccount(paymentID))
+
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should show the changes in dry-run mode", func() {
+		Expect(form3ctl("apply", "-f", dir, "--dry-run", "--prune")).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal(
+			"accounts/" + accountID + " updated (dry run)\n" +
+				`  attributes.bank_id: "400300" -> "400302"` + "\n" +
+				"accounts/" + paymentID + " created (dry run)\n" +
+				"accounts/" + extraID + " deleted (dry run)\n"))
+
+		Expect(form3ctl("get", "accounts", paymentID)).To(Equal(exitError))
+	})
+
+	It("should converge and prune", func() {
+		Expect(form3ctl("apply", "-f", dir, "--prune")).To(Equal(exitOK))
+
+		Expect(form3ctl("list", "accounts", "-o", "json")).To(Equal(exitOK))
+
+		var accounts []api.Account
+		Expect(json.Unmarshal(stdout.Bytes(), &accounts)).To(Succeed())
+		Expect(accounts).To(HaveLen(2))
+		Expect(accounts[0].Attributes.BankID).To(Equal("400302"))
+		Expect(accounts[1].ID).To(Equal(paymentID))
+
+		Expect(form3ctl("apply", "-f", filepath.Join(dir, "changed.json"))).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal("accounts/" + accountID + " unchanged\n"))
+	})
+
+	It("should report the resources which failed", func() {
+		invalid := newAccount(paymentID)
+		invalid.Attributes.Country = "XX"
+		writeManifest("missing.json", invalid)
+
+		Expect(form3ctl("apply", "-f", dir)).To(Equal(exitError))
+		Expect(stdout.String()).To(ContainSubstring("accounts/" + accountID + " updated\n"))
+		Expect(stdout.String()).To(ContainSubstring("accounts/" + paymentID + " failed: validation failed"))
+		Expect(stderr.String()).To(Equal("error: apply failed: 1 of 2 resources\n"))
+	})
+})
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/bulk"
+)
+
+// bulkResource resolves the optional RESOURCE argument of export and import,
+// accounts by default.
+func bulkResource(args []string) (*resource, error) {
+	switch len(args) {
+	case 0:
+		return lookup("accounts")
+	case 1:
+		return lookup(args[0])
+	}
+
+	return nil, errUsage
+}
+
+// bulkFormat returns format, or guesses it from file, NDJSON for stdin and
+// stdout.
+func bulkFormat(format, file string) (bulk.Format, error) {
+	if format != "" {
+		return bulk.Format(format), nil
+	}
+
+	if file == "" || file == "-" {
+		return bulk.FormatNDJSON, nil
+	}
+
+	return bulk.FormatOf(file)
+}
+
+var exportFlags struct {
+	file     string
+	format   string
+	columns  string
+	filter   filterFlag
+	pageSize int
+}
+
+var exportCommand = &command{
+	name:    "export",
+	usage:   "export [RESOURCE] [-f FILE] [--format ndjson|csv] [--columns COLUMNS]",
+	summary: "Stream every resource of a type, accounts by default, to NDJSON or CSV.",
+	flags: func(fs *flag.FlagSet) {
+		exportFlags.filter = filterFlag{}
+		fs.StringVar(&exportFlags.file, "f", "-", "file to write, stdout by default")
+		fs.StringVar(&exportFlags.format, "format", "", "ndjson or csv, guessed from the file extension by default")
+		fs.StringVar(&exportFlags.columns, "columns", "", "comma separated fields written to CSV, e.g. id,attributes.iban")
+		fs.Var(&exportFlags.filter, "filter", "only export resources whose field matches, as name=value (repeatable)")
+		fs.IntVar(&exportFlags.pageSize, "page-size", 0, "number of resources fetched per request")
+	},
+	run: func(ctx context.Context, c *cli, client pkg.Client, args []string) error {
+		res, err := bulkResource(args)
+		if err != nil {
+			return err
+		}
+
+		format, err := bulkFormat(exportFlags.format, exportFlags.file)
+		if err != nil {
+			return err
+		}
+
+		list, err := res.list(c.opts.parent)
+		if err != nil {
+			return err
+		}
+
+		opts := &bulk.ExportOptions{
+			Format:      format,
+			ListOptions: &pkg.ListOptions{PageSize: exportFlags.pageSize, Filter: exportFlags.filter.filter},
+		}
+
+		if exportFlags.columns != "" {
+			opts.Columns = strings.Split(exportFlags.columns, ",")
+		}
+
+		out := c.stdout
+
+		if exportFlags.file != "-" {
+			file, err := os.Create(exportFlags.file)
+			if err != nil {
+				return err
+			}
+
+			defer file.Close()
+
+			out = file
+		}
+
+		count, err := bulk.Export(ctx, client, out, list, opts)
+		if err != nil {
+			return err
+		}
+
+		if exportFlags.file != "-" {
+			fmt.Fprintf(c.stderr, "exported %d %s to %s\n", count, res.name, exportFlags.file)
+		}
+
+		return nil
+	},
+}
+
+var importFlags struct {
+	file           string
+	format         string
+	parallelism    int
+	checkpoint     string
+	organisationID string
+	skipExisting   bool
+	errors         string
+}
+
+// importRows runs the import, with the checkpoint from --checkpoint if set.
+func importRows(ctx context.Context, client pkg.Client, r io.Reader, kind string, opts *bulk.ImportOptions) (*bulk.ImportReport, error) {
+	if importFlags.checkpoint == "" {
+		return bulk.Import(ctx, client, r, kind, opts)
+	}
+
+	checkpoint, err := bulk.NewFileCheckpoint(importFlags.checkpoint)
+	if err != nil {
+		return &bulk.ImportReport{}, err
+	}
+
+	defer checkpoint.Close()
+
+	opts.Checkpoint = checkpoint
+
+	return bulk.Import(ctx, client, r, kind, opts)
+}
+
+var importCommand = &command{
+	name:    "import",
+	usage:   "import [RESOURCE] -f FILE [--parallelism N] [--checkpoint FILE] [--errors FILE]",
+	summary: "Create a resource per row of an NDJSON or CSV file written by export.",
+	flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&importFlags.file, "f", "", "file to read, or - for stdin")
+		fs.StringVar(&importFlags.format, "format", "", "ndjson or csv, guessed from the file extension by default")
+		fs.IntVar(&importFlags.parallelism, "parallelism", bulk.DefaultParallelism, "number of resources created at once")
+		fs.StringVar(&importFlags.checkpoint, "checkpoint", "", "file recording the rows done, to resume an interrupted import")
+		fs.StringVar(&importFlags.organisationID, "organisation-id", "", "organisation to import the resources into")
+		fs.BoolVar(&importFlags.skipExisting, "skip-existing", false, "skip the resources which already exist")
+		fs.StringVar(&importFlags.errors, "errors", "", "CSV file to write the rows which failed to")
+	},
+	run: func(ctx context.Context, c *cli, client pkg.Client, args []string) error {
+		if importFlags.file == "" {
+			return errUsage
+		}
+
+		res, err := bulkResource(args)
+		if err != nil {
+			return err
+		}
+
+		format, err := bulkFormat(importFlags.format, importFlags.file)
+		if err != nil {
+			return err
+		}
+
+		in := c.stdin
+
+		if importFlags.file != "-" {
+			file, err := os.Open(importFlags.file)
+			if err != nil {
+				return err
+			}
+
+			defer file.Close()
+
+			in = file
+		}
+
+		report, err := importRows(ctx, client, in, res.kind, &bulk.ImportOptions{
+			Format:         format,
+			Parallelism:    importFlags.parallelism,
+			OrganisationID: importFlags.organisationID,
+			SkipExisting:   importFlags.skipExisting,
+		})
+
+		fmt.Fprintf(c.stdout, "created %d, skipped %d, failed %d\n", report.Created, report.Skipped, len(report.Errors))
+
+		for _, rowErr := range report.Errors {
+			fmt.Fprintf(c.stdout, "  %s\n", rowErr)
+		}
+
+		if importFlags.errors != "" && errors.Is(err, bulk.ErrImportFailed) {
+			if writeErr := writeImportErrors(importFlags.errors, report); writeErr != nil {
+				return writeErr
+			}
+		}
+
+		return err
+	},
+}
+
+func writeImportErrors(path string, report *bulk.ImportReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := report.WriteErrors(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
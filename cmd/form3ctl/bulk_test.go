@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+var _ = Describe("export and import", func() {
+	var (
+		server *fakeapi.Server
+		dir    string
+		stdin  *bytes.Buffer
+		stdout *bytes.Buffer
+		stderr *bytes.Buffer
+	)
+
+	form3ctl := func(args ...string) int {
+		stdout.Reset()
+		stderr.Reset()
+
+		return run(context.TODO(), append(args, "--host", server.URL), stdin, stdout, stderr)
+	}
+
+	BeforeEach(func() {
+		server = fakeapi.NewServer()
+		Expect(server.Seed(newAccount(accountID), newAccount(paymentID))).To(Succeed())
+
+		var err error
+		dir, err = ioutil.TempDir("", "form3ctl")
+		Expect(err).NotTo(HaveOccurred())
+
+		stdin = &bytes.Buffer{}
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should export the selected columns", func() {
+		Expect(form3ctl("export", "--columns", "id,attributes.bank_id", "--format", "csv", "--page-size", "1")).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal("id,attributes.bank_id\n" + accountID + ",400300\n" + paymentID + ",400300\n"))
+
+		Expect(form3ctl("export", "--columns", "id,unknown", "--format", "csv")).To(Equal(exitError))
+		Expect(stderr.String()).To(Equal("error: unknown column unknown for api.Account\n"))
+	})
+
+	It("should import what was exported, and report the rows which failed", func() {
+		file := filepath.Join(dir, "accounts.csv")
+		Expect(form3ctl("export", "accounts", "-f", file)).To(Equal(exitOK))
+		Expect(stderr.String()).To(Equal("exported 2 accounts to " + file + "\n"))
+
+		server.Reset()
+		Expect(server.Seed(newAccount(paymentID))).To(Succeed())
+
+		errorsFile := filepath.Join(dir, "errors.csv")
+		Expect(form3ctl("import", "-f", file, "--errors", errorsFile, "--parallelism", "1")).To(Equal(exitError))
+		Expect(stdout.String()).To(HavePrefix("created 1, skipped 0, failed 1\n  row 2 (" + paymentID + "): conflict: "))
+		Expect(stderr.String()).To(Equal("error: import failed: 1 of 2 rows\n"))
+		Expect(ioutil.ReadFile(errorsFile)).To(ContainSubstring("2," + paymentID + ",conflict: "))
+
+		Expect(form3ctl("import", "-f", file, "--skip-existing")).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal("created 0, skipped 2, failed 0\n"))
+	})
+
+	It("should import NDJSON from stdin, resuming from a checkpoint", func() {
+		Expect(form3ctl("export")).To(Equal(exitOK))
+		exported := stdout.String()
+
+		server.Reset()
+
+		checkpoint := filepath.Join(dir, "checkpoint")
+		Expect(ioutil.WriteFile(checkpoint, []byte("2\n"), 0o600)).To(Succeed())
+
+		stdin.WriteString(exported)
+		Expect(form3ctl("import", "-f", "-", "--checkpoint", checkpoint)).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal("created 1, skipped 1, failed 0\n"))
+
+		Expect(form3ctl("get", "accounts", accountID)).To(Equal(exitOK))
+		Expect(form3ctl("get", "accounts", paymentID)).To(Equal(exitError))
+	})
+})
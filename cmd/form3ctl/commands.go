@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var commands = []*command{
+	getCommand,
+	listCommand,
+	describeCommand,
+	createCommand,
+	applyCommand,
+	deleteCommand,
+	seedCommand,
+	exportCommand,
+	importCommand,
+}
+
+// fetch resolves RESOURCE ID arguments and fetches the object.
+func fetch(ctx context.Context, c *cli, client pkg.Client, args []string) (*resource, api.Object, error) {
+	if len(args) != 2 {
+		return nil, nil, errUsage
+	}
+
+	res, err := lookup(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obj, err := res.object(args[1], c.opts.parent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := client.Fetch(ctx, obj); err != nil {
+		return nil, nil, err
+	}
+
+	return res, obj, nil
+}
+
+var getCommand = &command{
+	name:    "get",
+	usage:   "get RESOURCE ID",
+	summary: "Fetch a resource.",
+	run: func(ctx context.Context, c *cli, client pkg.Client, args []string) error {
+		p, err := c.printer()
+		if err != nil {
+			return err
+		}
+
+		res, obj, err := fetch(ctx, c, client, args)
+		if err != nil {
+			return err
+		}
+
+		return p.print(res, []api.Object{obj}, false)
+	},
+}
+
+var describeCommand = &command{
+	name:    "describe",
+	usage:   "describe RESOURCE ID",
+	summary: "Fetch a resource and show each of its fields on its own line.",
+	run: func(ctx context.Context, c *cli, client pkg.Client, args []string) error {
+		res, obj, err := fetch(ctx, c, client, args)
+		if err != nil {
+			return err
+		}
+
+		return (&printer{out: c.stdout}).describe(res, obj)
+	},
+}
+
+// filterFlag collects --filter name=value flags. Comma separated values
+// match any of them.
+type filterFlag struct {
+	filter *pkg.Filter
+}
+
+func (f *filterFlag) String() string {
+	return ""
+}
+
+func (f *filterFlag) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected name=value, got %q", value)
+	}
+
+	if f.filter == nil {
+		f.filter = pkg.NewFilter()
+	}
+
+	f.filter.Where(parts[0], strings.Split(parts[1], ",")...)
+
+	return nil
+}
+
+var listFlags struct {
+	filter   filterFlag
+	pageSize int
+}
+
+var listCommand = &command{
+	name:    "list",
+	usage:   "list RESOURCE",
+	summary: "List every resource of a type, following pagination.",
+	flags: func(fs *flag.FlagSet) {
+		listFlags.filter = filterFlag{}
+		fs.Var(&listFlags.filter, "filter", "only list resources whose field matches, as name=value (repeatable)")
+		fs.IntVar(&listFlags.pageSize, "page-size", 0, "number of resources fetched per request")
+	},
+	run: func(ctx context.Context, c *cli, client pkg.Client, args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+
+		p, err := c.printer()
+		if err != nil {
+			return err
+		}
+
+		res, err := lookup(args[0])
+		if err != nil {
+			return err
+		}
+
+		list, err := res.list(c.opts.parent)
+		if err != nil {
+			return err
+		}
+
+		listOptions := &pkg.ListOptions{PageSize: listFlags.pageSize, Filter: listFlags.filter.filter}
+		if err := client.List(ctx, list, listOptions); err != nil {
+			return err
+		}
+
+		return p.print(res, items(list), true)
+	},
+}
+
+// decodeManifest decodes an object from body, either wrapped in "data" like
+// API responses and fixtures, or bare. Its resource is named by kind, or
+// guessed from the type of the object.
+func decodeManifest(body []byte, kind string) (*resource, api.Object, error) {
+	var wrapped struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return nil, nil, err
+	}
+
+	if len(wrapped.Data) != 0 {
+		body = wrapped.Data
+	}
+
+	if kind == "" {
+		var meta struct {
+			Type string `json:"type"`
+		}
+
+		if err := json.Unmarshal(body, &meta); err != nil {
+			return nil, nil, err
+		}
+
+		if meta.Type == "" {
+			return nil, nil, errors.New("missing type, pass the resource as argument")
+		}
+
+		kind = meta.Type
+	}
+
+	res, err := lookup(kind)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obj, err := api.Schema.NewObj(res.kind)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := json.Unmarshal(body, obj); err != nil {
+		return nil, nil, err
+	}
+
+	return res, obj, nil
+}
+
+var createFlags struct {
+	file string
+}
+
+var createCommand = &command{
+	name:    "create",
+	usage:   "create -f FILE [RESOURCE]",
+	summary: "Create a resource from a JSON file, or from stdin with -f -.",
+	flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&createFlags.file, "f", "", "JSON file describing the resource")
+	},
+	run: func(ctx context.Context, c *cli, client pkg.Client, args []string) error {
+		if createFlags.file == "" || len(args) > 1 {
+			return errUsage
+		}
+
+		p, err := c.printer()
+		if err != nil {
+			return err
+		}
+
+		var body []byte
+		if createFlags.file == "-" {
+			body, err = ioutil.ReadAll(c.stdin)
+		} else {
+			body, err = ioutil.ReadFile(createFlags.file)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		kind := ""
+		if len(args) == 1 {
+			kind = args[0]
+		}
+
+		res, obj, err := decodeManifest(body, kind)
+		if err != nil {
+			return fmt.Errorf("%s: %w", createFlags.file, err)
+		}
+
+		if c.opts.parent != "" {
+			if err := setParent(obj, res, c.opts.parent); err != nil {
+				return err
+			}
+		}
+
+		if err := client.Create(ctx, obj); err != nil {
+			return err
+		}
+
+		return p.print(res, []api.Object{obj}, false)
+	},
+}
+
+var deleteFlags struct {
+	version int
+}
+
+var deleteCommand = &command{
+	name:    "delete",
+	usage:   "delete RESOURCE ID",
+	summary: "Delete a resource, at its current version unless --version is set.",
+	flags: func(fs *flag.FlagSet) {
+		fs.IntVar(&deleteFlags.version, "version", -1, "version of the resource to delete")
+	},
+	run: func(ctx context.Context, c *cli, client pkg.Client, args []string) error {
+		if len(args) != 2 {
+			return errUsage
+		}
+
+		res, err := lookup(args[0])
+		if err != nil {
+			return err
+		}
+
+		obj, err := res.object(args[1], c.opts.parent)
+		if err != nil {
+			return err
+		}
+
+		if deleteFlags.version < 0 {
+			if err := client.Fetch(ctx, obj); err != nil {
+				return err
+			}
+		} else if setter, ok := obj.(interface{ SetVersion(int) }); ok {
+			setter.SetVersion(deleteFlags.version)
+		}
+
+		if err := client.Delete(ctx, obj); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(c.stdout, "%s/%s deleted\n", res.name, obj.GetID())
+
+		return err
+	},
+}
@@ -0,0 +1,165 @@
+// Command form3ctl reads and manages the resources of the Form3 API
+// registered in api.Schema:
+//
+//	form3ctl list accounts --filter country=GB -o yaml
+//	form3ctl get payment ad27e265-9605-4b4b-a0e5-3003ea9cc4dc
+//	form3ctl create -f fixtures/fetch_api.Account_personal.json
+//	form3ctl delete account ad27e265-9605-4b4b-a0e5-3003ea9cc4dc
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/vtemian/form3/pkg"
+)
+
+// Exit codes.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+var errUsage = errors.New("usage")
+
+// cli holds what a command needs to run.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	opts   *options
+}
+
+func (c *cli) printer() (*printer, error) {
+	return newPrinter(c.stdout, c.opts.output)
+}
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	// flags registers the flags specific to the command, if any.
+	flags func(fs *flag.FlagSet)
+	run   func(ctx context.Context, c *cli, client pkg.Client, args []string) error
+}
+
+func find(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// parse parses flags wherever they appear among the positional arguments,
+// which are returned.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: form3ctl COMMAND [ARGS] [FLAGS]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.usage, cmd.summary)
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Resources: %s\n", strings.Join(resourceNames(), ", "))
+	fmt.Fprintln(w, "Run 'form3ctl COMMAND -h' for the flags of a command.")
+}
+
+// run executes the command line args and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return exitOK
+	}
+
+	cmd := find(args[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+
+		return exitUsage
+	}
+
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr, opts: &options{}}
+
+	fs := flag.NewFlagSet("form3ctl "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: form3ctl %s\n\n%s\n\nFlags:\n", cmd.usage, cmd.summary)
+		fs.PrintDefaults()
+	}
+
+	c.opts.register(fs)
+
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+
+	positional, err := parse(fs, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	if err != nil {
+		return exitUsage
+	}
+
+	client, err := c.opts.client()
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %s\n", err)
+		return exitError
+	}
+
+	if err := cmd.run(ctx, c, client, positional); err != nil {
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+			return exitUsage
+		}
+
+		fmt.Fprintf(stderr, "error: %s\n", err)
+
+		return exitError
+	}
+
+	return exitOK
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+
+	os.Exit(code)
+}
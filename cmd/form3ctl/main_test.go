@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+const (
+	accountID = "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"
+	paymentID = "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
+)
+
+func newAccount(id string) *api.Account {
+	account := api.NewAccount(id, 0)
+	account.Type = "accounts"
+	account.OrganisationID = "721763e9-b2e2-4ebb-8de9-b440e3cf23a6"
+	account.Attributes = api.AccountAttributes{
+		Country:               "GB",
+		BaseCurrency:          "GBP",
+		BankID:                "400300",
+		BankIDCode:            "GBDSC",
+		BIC:                   "NWBKGB22",
+		AccountClassification: api.AccountClassificationPersonal,
+	}
+
+	return account
+}
+
+var _ = Describe("form3ctl", func() {
+	var (
+		server *fakeapi.Server
+		stdin  *bytes.Buffer
+		stdout *bytes.Buffer
+		stderr *bytes.Buffer
+	)
+
+	form3ctl := func(args ...string) int {
+		stdout.Reset()
+		stderr.Reset()
+
+		return run(context.TODO(), append(args, "--host", server.URL), stdin, stdout, stderr)
+	}
+
+	BeforeEach(func() {
+		server = fakeapi.NewServer()
+		Expect(server.Seed(newAccount(accountID))).To(Succeed())
+
+		stdin = &bytes.Buffer{}
+		stdout = &bytes.Buffer{}
+		stderr = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should print usage", func() {
+		Expect(run(context.TODO(), nil, stdin, stdout, stderr)).To(Equal(exitOK))
+		Expect(stdout.String()).To(ContainSubstring("get RESOURCE ID"))
+		Expect(stdout.String()).To(ContainSubstring("Resources: accounts, payments, recalls"))
+
+		Expect(form3ctl("unknown")).To(Equal(exitUsage))
+		Expect(form3ctl("get", "accounts")).To(Equal(exitUsage))
+	})
+
+	It("should get resources as a table", func() {
+		Expect(form3ctl("get", "account", accountID)).To(Equal(exitOK))
+
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		Expect(lines).To(HaveLen(2))
+		Expect(strings.Fields(lines[0])).To(Equal([]string{"ID", "VERSION", "COUNTRY", "BANK", "ID", "ACCOUNT", "NUMBER", "IBAN", "STATUS"}))
+		Expect(strings.Fields(lines[1])).To(Equal([]string{accountID, "0", "GB", "400300"}))
+	})
+
+	It("should get resources as JSON and YAML", func() {
+		Expect(form3ctl("get", "accounts", accountID, "-o", "json")).To(Equal(exitOK))
+		Expect(stdout.String()).To(ContainSubstring(`"bank_id": "400300"`))
+
+		Expect(form3ctl("get", "accounts", accountID, "-o", "yaml")).To(Equal(exitOK))
+		Expect(stdout.String()).To(ContainSubstring("bank_id: \"400300\""))
+		Expect(stdout.String()).To(ContainSubstring("id: " + accountID))
+
+		Expect(form3ctl("get", "accounts", accountID, "-o", "xml")).To(Equal(exitError))
+	})
+
+	It("should report errors", func() {
+		Expect(form3ctl("get", "accounts", paymentID)).To(Equal(exitError))
+		Expect(stderr.String()).To(HavePrefix("error: "))
+
+		Expect(form3ctl("get", "widgets", paymentID)).To(Equal(exitError))
+		Expect(stderr.String()).To(ContainSubstring(`unknown resource "widgets"`))
+	})
+
+	It("should list resources with filters", func() {
+		Expect(server.Seed(newAccount(paymentID))).To(Succeed())
+
+		Expect(form3ctl("list", "accounts", "--filter", "bank_id=400300", "--page-size", "1")).To(Equal(exitOK))
+		Expect(strings.Split(strings.TrimSpace(stdout.String()), "\n")).To(HaveLen(3))
+
+		Expect(form3ctl("list", "accounts", "--filter", "colour=red")).To(Equal(exitError))
+		Expect(stderr.String()).To(ContainSubstring("unknown filter colour"))
+	})
+
+	It("should describe resources", func() {
+		Expect(form3ctl("describe", "accounts", accountID)).To(Equal(exitOK))
+		Expect(stdout.String()).To(ContainSubstring("Kind:"))
+		Expect(stdout.String()).To(MatchRegexp(`attributes\.bank_id:\s+400300`))
+		Expect(stdout.String()).To(MatchRegexp(`Endpoint:\s+organisation/accounts/` + accountID))
+	})
+
+	It("should create resources from stdin and guess their type", func() {
+		stdin.WriteString(`{"data": {"id": "` + paymentID + `", "type": "accounts", "organisation_id": "721763e9-b2e2-4ebb-8de9-b440e3cf23a6",
+			"attributes": {"country": "GB", "bank_id": "400300", "bank_id_code": "GBDSC", "bic": "NWBKGB22", "account_classification": "Personal"}}}`)
+
+		Expect(form3ctl("create", "-f", "-", "-o", "json")).To(Equal(exitOK))
+		Expect(stdout.String()).To(ContainSubstring(paymentID))
+
+		Expect(form3ctl("get", "accounts", paymentID)).To(Equal(exitOK))
+	})
+
+	It("should create fixtures", func() {
+		Expect(form3ctl("create", "-f", "../../fixtures/fetch_api.Account_uk_payee.json")).To(Equal(exitOK))
+		Expect(stdout.String()).To(ContainSubstring("ID"))
+	})
+
+	It("should seed fixtures", func() {
+		Expect(form3ctl("seed", "-f", "../../fixtures")).To(Equal(exitOK))
+		Expect(stdout.String()).To(HavePrefix("deleted api.Account " + accountID + "\n"))
+		Expect(stdout.String()).To(ContainSubstring("created api.Account 93bfaa94-9e48-402d-9744-6ef85c6303b0\n"))
+
+		Expect(form3ctl("get", "accounts", accountID)).To(Equal(exitError))
+	})
+
+	It("should delete resources at their current version", func() {
+		Expect(form3ctl("delete", "accounts", accountID, "--version", "3")).To(Equal(exitError))
+
+		Expect(form3ctl("delete", "accounts", accountID)).To(Equal(exitOK))
+		Expect(stdout.String()).To(Equal("accounts/" + accountID + " deleted\n"))
+
+		Expect(form3ctl("get", "accounts", accountID)).To(Equal(exitError))
+	})
+
+	It("should require the parent of nested resources", func() {
+		Expect(form3ctl("list", "submissions")).To(Equal(exitError))
+		Expect(stderr.String()).To(ContainSubstring("set --parent"))
+
+		Expect(form3ctl("list", "payment_submissions", "--parent", paymentID)).To(Equal(exitError))
+		Expect(stderr.String()).To(HavePrefix("error: "))
+	})
+})
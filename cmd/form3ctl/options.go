@@ -0,0 +1,119 @@
+package main
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/auth"
+)
+
+const defaultHost = "http://localhost:8080"
+
+// options are shared by every command. Each flag defaults to an environment
+// variable, so operators can configure form3ctl once per shell.
+type options struct {
+	host       string
+	apiVersion string
+	output     string
+	parent     string
+
+	keyID      string
+	privateKey string
+
+	tokenURL     string
+	clientID     string
+	clientSecret string
+}
+
+// getenv returns the first variable of keys that is set, or def.
+func getenv(def string, keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+
+	return def
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.host, "host", getenv(defaultHost, "FORM3_API_HOST", "TEST_API_HOST"),
+		"base URL of the API (FORM3_API_HOST, TEST_API_HOST)")
+	fs.StringVar(&o.apiVersion, "api-version", getenv("v1", "FORM3_API_VERSION"), "API version (FORM3_API_VERSION)")
+	fs.StringVar(&o.output, "o", getenv(formatTable, "FORM3_OUTPUT"), "output format: table, json or yaml (FORM3_OUTPUT)")
+	fs.StringVar(&o.parent, "parent", "", "ID of the parent of nested resources, e.g. the payment of submissions")
+
+	fs.StringVar(&o.keyID, "key-id", getenv("", "FORM3_KEY_ID"), "ID of the key signing requests (FORM3_KEY_ID)")
+	fs.StringVar(&o.privateKey, "private-key", getenv("", "FORM3_PRIVATE_KEY"),
+		"PEM file of the RSA key signing requests (FORM3_PRIVATE_KEY)")
+
+	fs.StringVar(&o.tokenURL, "token-url", getenv("", "FORM3_TOKEN_URL"), "OAuth2 token endpoint (FORM3_TOKEN_URL)")
+	fs.StringVar(&o.clientID, "client-id", getenv("", "FORM3_CLIENT_ID"), "OAuth2 client ID (FORM3_CLIENT_ID)")
+	fs.StringVar(&o.clientSecret, "client-secret", getenv("", "FORM3_CLIENT_SECRET"),
+		"OAuth2 client secret (FORM3_CLIENT_SECRET)")
+}
+
+func loadPrivateKey(file string) (*rsa.PrivateKey, error) {
+	body, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", file)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an RSA key", file)
+	}
+
+	return rsaKey, nil
+}
+
+func (o *options) client() (pkg.Client, error) {
+	opts := []pkg.Option{
+		pkg.WithBaseURL(strings.TrimSuffix(o.host, "/")),
+		pkg.WithVersion(o.apiVersion),
+	}
+
+	switch {
+	case o.keyID != "" || o.privateKey != "":
+		if o.keyID == "" || o.privateKey == "" {
+			return nil, errors.New("signing requests needs both --key-id and --private-key")
+		}
+
+		key, err := loadPrivateKey(o.privateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, pkg.WithSigningKey(o.keyID, key))
+	case o.clientID != "" || o.clientSecret != "":
+		if o.tokenURL == "" || o.clientID == "" || o.clientSecret == "" {
+			return nil, errors.New("client credentials need --token-url, --client-id and --client-secret")
+		}
+
+		opts = append(opts, pkg.WithTokenSource(auth.NewClientCredentials(o.tokenURL, o.clientID, o.clientSecret)))
+	}
+
+	return pkg.NewClient(opts...), nil
+}
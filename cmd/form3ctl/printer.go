@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+// Output formats accepted by -o.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+)
+
+type column struct {
+	header string
+	field  string
+}
+
+// columns are the fields shown in tables after the ID and the version, by
+// resource name.
+var columns = map[string][]column{
+	"accounts": {
+		{"COUNTRY", "attributes.country"},
+		{"BANK ID", "attributes.bank_id"},
+		{"ACCOUNT NUMBER", "attributes.account_number"},
+		{"IBAN", "attributes.iban"},
+		{"STATUS", "attributes.status"},
+	},
+	"payments": {
+		{"AMOUNT", "attributes.amount"},
+		{"CURRENCY", "attributes.currency"},
+		{"SCHEME", "attributes.payment_scheme"},
+		{"REFERENCE", "attributes.reference"},
+	},
+	"subscriptions": {
+		{"RECORD TYPE", "attributes.record_type"},
+		{"EVENT TYPE", "attributes.event_type"},
+		{"CALLBACK URI", "attributes.callback_uri"},
+	},
+}
+
+var defaultColumns = []column{
+	{"STATUS", "attributes.status"},
+}
+
+// items returns pointers to the items of list.
+func items(list api.Object) []api.Object {
+	v := reflect.Indirect(reflect.ValueOf(list)).FieldByName("Items")
+
+	objs := make([]api.Object, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		objs = append(objs, v.Index(i).Addr().Interface().(api.Object))
+	}
+
+	return objs
+}
+
+// generic converts obj to the maps, slices and values JSON decodes into, so
+// it's printed with its JSON field names whatever the format.
+func generic(obj interface{}) (interface{}, error) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// flatten maps the dotted path of every leaf of value to its text.
+func flatten(prefix string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flatten(join(prefix, key), child, fields)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), child, fields)
+		}
+	case nil:
+	default:
+		fields[prefix] = fmt.Sprint(v)
+	}
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+type printer struct {
+	out    io.Writer
+	format string
+}
+
+func newPrinter(out io.Writer, format string) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{out: out, format: format}, nil
+	}
+
+	return nil, fmt.Errorf("unknown output format %q, expected one of: %s, %s, %s", format, formatTable, formatJSON, formatYAML)
+}
+
+// print writes a single object, or a list of them when list is true.
+func (p *printer) print(res *resource, objs []api.Object, list bool) error {
+	var value interface{} = objs
+	if !list && len(objs) == 1 {
+		value = objs[0]
+	}
+
+	switch p.format {
+	case formatJSON:
+		body, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(p.out, "%s\n", body)
+
+		return err
+	case formatYAML:
+		data, err := generic(value)
+		if err != nil {
+			return err
+		}
+
+		body, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		_, err = p.out.Write(body)
+
+		return err
+	}
+
+	return p.table(res, objs)
+}
+
+func (p *printer) table(res *resource, objs []api.Object) error {
+	cols, exists := columns[res.name]
+	if !exists {
+		cols = defaultColumns
+	}
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 3, ' ', 0)
+
+	headers := []string{"ID", "VERSION"}
+	for _, col := range cols {
+		headers = append(headers, col.header)
+	}
+
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+	for _, obj := range objs {
+		data, err := generic(obj)
+		if err != nil {
+			return err
+		}
+
+		fields := map[string]string{}
+		flatten("", data, fields)
+
+		row := []string{obj.GetID(), fmt.Sprint(obj.GetVersion())}
+		for _, col := range cols {
+			row = append(row, fields[col.field])
+		}
+
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return w.Flush()
+}
+
+// describe writes every field of obj on its own line, sorted by path.
+func (p *printer) describe(res *resource, obj api.Object) error {
+	data, err := generic(obj)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]string{}
+	flatten("", data, fields)
+
+	paths := make([]string, 0, len(fields))
+	for field := range fields {
+		paths = append(paths, field)
+	}
+
+	sort.Strings(paths)
+
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Kind:\t%s\n", kindOf(res.kind))
+
+	if endpoint, err := api.Schema.ObjectPath(obj); err == nil {
+		fmt.Fprintf(w, "Endpoint:\t%s\n", endpoint)
+	}
+
+	for _, field := range paths {
+		fmt.Fprintf(w, "%s:\t%s\n", field, fields[field])
+	}
+
+	return w.Flush()
+}
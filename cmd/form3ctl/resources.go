@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+// resource is a type registered in api.Schema, named after the last segment
+// of its collection endpoint, e.g. accounts or submissions.
+type resource struct {
+	name     string
+	kind     string
+	listKind string
+	// parent is the name of the resource this one is nested under, if any.
+	parent string
+}
+
+func kindOf(kind string) string {
+	return strings.TrimPrefix(kind, "api.")
+}
+
+// resources lists the types of api.Schema which have a matching list type,
+// sorted by name.
+func resources() []*resource {
+	byKind := map[string]*resource{}
+
+	for _, kind := range api.Schema.Kinds() {
+		listKind := kind + "List"
+
+		list, err := api.Schema.NewObj(listKind)
+		if err != nil {
+			continue
+		}
+
+		endpoint, err := api.Schema.GetEndpointForObj(list)
+		if err != nil {
+			continue
+		}
+
+		byKind[kind] = &resource{name: path.Base(endpoint), kind: kind, listKind: listKind}
+	}
+
+	result := make([]*resource, 0, len(byKind))
+
+	for kind, res := range byKind {
+		obj, _ := api.Schema.NewObj(kind)
+		if parent, exists := api.Schema.ParentOf(obj); exists && byKind[parent] != nil {
+			res.parent = byKind[parent].name
+		}
+
+		result = append(result, res)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+
+	return result
+}
+
+// lookup finds a resource by name, singular name, kind or record type,
+// ignoring case.
+func lookup(name string) (*resource, error) {
+	name = strings.ToLower(name)
+
+	for _, res := range resources() {
+		if name == res.name || name == strings.TrimSuffix(res.name, "s") || name == strings.ToLower(kindOf(res.kind)) ||
+			name == res.recordType() {
+			return res, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown resource %q, expected one of: %s", name, strings.Join(resourceNames(), ", "))
+}
+
+// recordType is the type of the records of the resource in the API, e.g.
+// payment_submissions for submissions.
+func (r *resource) recordType() string {
+	if r.parent == "" {
+		return r.name
+	}
+
+	return strings.TrimSuffix(r.parent, "s") + "_" + r.name
+}
+
+func resourceNames() []string {
+	var names []string
+	for _, res := range resources() {
+		names = append(names, res.name)
+	}
+
+	return names
+}
+
+func setParent(obj interface{}, res *resource, parentID string) error {
+	if res.parent == "" {
+		return nil
+	}
+
+	if parentID == "" {
+		return fmt.Errorf("%s are nested under %s, set --parent", res.name, res.parent)
+	}
+
+	setter, ok := obj.(api.ParentSetter)
+	if !ok {
+		return fmt.Errorf("%s can't be nested under %s", res.kind, res.parent)
+	}
+
+	setter.SetParentID(parentID)
+
+	return nil
+}
+
+// object returns an empty object of the resource, with the given ID.
+func (r *resource) object(id, parentID string) (api.Object, error) {
+	obj, err := api.Schema.NewObj(r.kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if setter, ok := obj.(interface{ SetID(string) }); ok {
+		setter.SetID(id)
+	}
+
+	if err := setParent(obj, r, parentID); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// list returns an empty list of the resource.
+func (r *resource) list(parentID string) (api.Object, error) {
+	list, err := api.Schema.NewObj(r.listKind)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setParent(list, r, parentID); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
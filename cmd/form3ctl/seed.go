@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/seed"
+)
+
+var seedFlags struct {
+	dir string
+}
+
+var seedCommand = &command{
+	name:    "seed",
+	usage:   "seed [-f DIR]",
+	summary: "Replace the resources of the fixture types with the fixtures, e.g. fetch_api.Account_personal.json.",
+	flags: func(fs *flag.FlagSet) {
+		fs.StringVar(&seedFlags.dir, "f", "fixtures", "directory of the fixtures")
+	},
+	run: func(ctx context.Context, c *cli, client pkg.Client, args []string) error {
+		if len(args) != 0 {
+			return errUsage
+		}
+
+		report, err := seed.Run(ctx, client, seedFlags.dir)
+		report.Print(c.stdout)
+
+		return err
+	},
+}
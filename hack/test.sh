@@ -2,7 +2,7 @@
 
 HOST=${TEST_API_HOST:-"http://localhost:8080"}
 
-go get github.com/onsi/ginkgo/ginkgo
+go install github.com/onsi/ginkgo/ginkgo@v1.14.1
 go mod download
 make check
 make tests
@@ -0,0 +1,265 @@
+package api
+
+import "regexp"
+
+const accountType = "accounts"
+
+// countryRule holds the formats accepted for the bank identifiers of accounts
+// held in a country. A nil bankID means the country doesn't use bank IDs.
+type countryRule struct {
+	bankID         *regexp.Regexp
+	bankIDRequired bool
+	bankIDCode     string
+	bicRequired    bool
+	accountNumber  *regexp.Regexp
+}
+
+// countryRules follows the per country requirements of the accounts API.
+var countryRules = map[string]countryRule{
+	"AU": {
+		bankID:        regexp.MustCompile(`^[0-9]{6}$`),
+		bankIDCode:    "AUBSB",
+		bicRequired:   true,
+		accountNumber: regexp.MustCompile(`^[1-9][0-9]{5,9}$`),
+	},
+	"BE": {
+		bankID:         regexp.MustCompile(`^[0-9]{3}$`),
+		bankIDRequired: true,
+		bankIDCode:     "BE",
+		accountNumber:  regexp.MustCompile(`^[0-9]{7}$`),
+	},
+	"CA": {
+		bankID:        regexp.MustCompile(`^0[0-9]{8}$`),
+		bankIDCode:    "CACPA",
+		bicRequired:   true,
+		accountNumber: regexp.MustCompile(`^[0-9]{7,12}$`),
+	},
+	"CH": {
+		bankID:         regexp.MustCompile(`^[0-9]{5}$`),
+		bankIDRequired: true,
+		bankIDCode:     "CHBCC",
+		accountNumber:  regexp.MustCompile(`^[A-Z0-9]{12}$`),
+	},
+	"DE": {
+		bankID:         regexp.MustCompile(`^[0-9]{8}$`),
+		bankIDRequired: true,
+		bankIDCode:     "DEBLZ",
+		accountNumber:  regexp.MustCompile(`^[0-9]{7,10}$`),
+	},
+	"ES": {
+		bankID:         regexp.MustCompile(`^[0-9]{8}$`),
+		bankIDRequired: true,
+		bankIDCode:     "ESNCC",
+		accountNumber:  regexp.MustCompile(`^[0-9]{10}$`),
+	},
+	"FR": {
+		bankID:         regexp.MustCompile(`^[0-9]{10}$`),
+		bankIDRequired: true,
+		bankIDCode:     "FR",
+		accountNumber:  regexp.MustCompile(`^[A-Z0-9]{10}$`),
+	},
+	"GB": {
+		bankID:         regexp.MustCompile(`^[0-9]{6}$`),
+		bankIDRequired: true,
+		bankIDCode:     "GBDSC",
+		bicRequired:    true,
+		accountNumber:  regexp.MustCompile(`^[0-9]{8}$`),
+	},
+	"GR": {
+		bankID:         regexp.MustCompile(`^[0-9]{7}$`),
+		bankIDRequired: true,
+		bankIDCode:     "GRBIC",
+		accountNumber:  regexp.MustCompile(`^[0-9]{16}$`),
+	},
+	"HK": {
+		bankID:        regexp.MustCompile(`^[0-9]{3}$`),
+		bankIDCode:    "HKNCC",
+		bicRequired:   true,
+		accountNumber: regexp.MustCompile(`^[0-9]{9,12}$`),
+	},
+	"IT": {
+		bankID:         regexp.MustCompile(`^[0-9]{10,11}$`),
+		bankIDRequired: true,
+		bankIDCode:     "ITNCC",
+		accountNumber:  regexp.MustCompile(`^[A-Z0-9]{12}$`),
+	},
+	"LU": {
+		bankID:         regexp.MustCompile(`^[0-9]{3}$`),
+		bankIDRequired: true,
+		bankIDCode:     "LULUX",
+		accountNumber:  regexp.MustCompile(`^[A-Z0-9]{13}$`),
+	},
+	"NL": {
+		bicRequired:   true,
+		accountNumber: regexp.MustCompile(`^[0-9]{10}$`),
+	},
+	"PL": {
+		bankID:         regexp.MustCompile(`^[0-9]{8}$`),
+		bankIDRequired: true,
+		bankIDCode:     "PLKNR",
+		accountNumber:  regexp.MustCompile(`^[0-9]{16}$`),
+	},
+	"PT": {
+		bankID:         regexp.MustCompile(`^[0-9]{8}$`),
+		bankIDRequired: true,
+		bankIDCode:     "PTNCC",
+		accountNumber:  regexp.MustCompile(`^[0-9]{11}$`),
+	},
+	"US": {
+		bankID:         regexp.MustCompile(`^[0-9]{9}$`),
+		bankIDRequired: true,
+		bankIDCode:     "USABA",
+		bicRequired:    true,
+		accountNumber:  regexp.MustCompile(`^[0-9]{6,17}$`),
+	},
+}
+
+// Formats used for countries without specific rules.
+var (
+	bankIDRegexp        = regexp.MustCompile(`^[A-Z0-9]{0,16}$`)
+	bankIDCodeRegexp    = regexp.MustCompile(`^[A-Z]{0,16}$`)
+	accountNumberRegexp = regexp.MustCompile(`^[A-Z0-9]{0,64}$`)
+)
+
+var accountClassifications = []AccountClassification{
+	AccountClassificationPersonal,
+	AccountClassificationBusiness,
+}
+
+var accountStatuses = []AccountStatus{
+	AccountStatusPending,
+	AccountStatusConfirmed,
+	AccountStatusFailed,
+}
+
+// Validate checks the account against the rules enforced by the accounts
+// API, so invalid accounts are rejected without a round trip.
+func (a Account) Validate() error { // nolint: gocritic
+	errs := ValidationErrors{}
+
+	if !IsUUID(a.ID) {
+		errs.add("id", a.ID, "must be a valid uuid")
+	}
+
+	if !IsUUID(a.OrganisationID) {
+		errs.add("organisation_id", a.OrganisationID, "must be a valid uuid")
+	}
+
+	if a.Type != accountType {
+		errs.add("type", a.Type, "should be one of [%s]", accountType)
+	}
+
+	a.Attributes.validate(&errs)
+
+	return errs.err()
+}
+
+func (a *AccountAttributes) validate(errs *ValidationErrors) {
+	switch {
+	case a.Country == "":
+		errs.add("attributes.country", a.Country, "is required")
+	case !IsCountryCode(a.Country):
+		errs.add("attributes.country", a.Country, "must be an ISO 3166-1 country code")
+	}
+
+	if a.BaseCurrency != "" && !IsCurrencyCode(a.BaseCurrency) {
+		errs.add("attributes.base_currency", a.BaseCurrency, "must be an ISO 4217 currency code")
+	}
+
+	if !containsClassification(a.AccountClassification) {
+		errs.add("attributes.account_classification", a.AccountClassification,
+			"should be one of %v", accountClassifications)
+	}
+
+	if a.Status != "" && !containsStatus(a.Status) {
+		errs.add("attributes.status", a.Status, "should be one of %v", accountStatuses)
+	}
+
+	if a.BIC != "" {
+		if err := a.BIC.Validate(); err != nil {
+			errs.add("attributes.bic", a.BIC, "is not valid: %s", err)
+		}
+	}
+
+	if a.IBAN != "" {
+		err := a.IBAN.Validate()
+
+		switch {
+		case err != nil:
+			errs.add("attributes.iban", a.IBAN, "is not valid: %s", err)
+		case IsCountryCode(a.Country) && a.IBAN.CountryCode() != a.Country:
+			errs.add("attributes.iban", a.IBAN, "should belong to country %s", a.Country)
+		}
+	}
+
+	rule, exists := countryRules[a.Country]
+	if !exists {
+		a.validateGeneric(errs)
+		return
+	}
+
+	a.validateCountry(errs, &rule)
+}
+
+func (a *AccountAttributes) validateGeneric(errs *ValidationErrors) {
+	if !bankIDRegexp.MatchString(a.BankID) {
+		errs.add("attributes.bank_id", a.BankID, "should match '%s'", bankIDRegexp)
+	}
+
+	if !bankIDCodeRegexp.MatchString(a.BankIDCode) {
+		errs.add("attributes.bank_id_code", a.BankIDCode, "should match '%s'", bankIDCodeRegexp)
+	}
+
+	if !accountNumberRegexp.MatchString(a.AccountNumber) {
+		errs.add("attributes.account_number", a.AccountNumber, "should match '%s'", accountNumberRegexp)
+	}
+}
+
+func (a *AccountAttributes) validateCountry(errs *ValidationErrors, rule *countryRule) {
+	switch {
+	case rule.bankID == nil && a.BankID != "":
+		errs.add("attributes.bank_id", a.BankID, "is not supported for country %s", a.Country)
+	case rule.bankIDRequired && a.BankID == "":
+		errs.add("attributes.bank_id", a.BankID, "is required for country %s", a.Country)
+	case rule.bankID != nil && a.BankID != "" && !rule.bankID.MatchString(a.BankID):
+		errs.add("attributes.bank_id", a.BankID, "should match '%s' for country %s", rule.bankID, a.Country)
+	}
+
+	switch {
+	case rule.bankIDCode == "" && a.BankIDCode != "":
+		errs.add("attributes.bank_id_code", a.BankIDCode, "is not supported for country %s", a.Country)
+	case rule.bankIDRequired && a.BankIDCode == "":
+		errs.add("attributes.bank_id_code", a.BankIDCode, "is required for country %s", a.Country)
+	case a.BankIDCode != "" && a.BankIDCode != rule.bankIDCode:
+		errs.add("attributes.bank_id_code", a.BankIDCode, "should be %s for country %s", rule.bankIDCode, a.Country)
+	}
+
+	if rule.bicRequired && a.BIC == "" {
+		errs.add("attributes.bic", a.BIC, "is required for country %s", a.Country)
+	}
+
+	if a.AccountNumber != "" && !rule.accountNumber.MatchString(a.AccountNumber) {
+		errs.add("attributes.account_number", a.AccountNumber,
+			"should match '%s' for country %s", rule.accountNumber, a.Country)
+	}
+}
+
+func containsClassification(classification AccountClassification) bool {
+	for _, valid := range accountClassifications {
+		if classification == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsStatus(status AccountStatus) bool {
+	for _, valid := range accountStatuses {
+		if status == valid {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidBIC = errors.New("invalid bic")
+
+var bicRegexp = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+
+// primaryOffice is the branch code of 8 character BICs.
+const primaryOffice = "XXX"
+
+// BIC is a Business Identifier Code (ISO 9362), e.g. NWBKGB22 or
+// DEUTDEFF500.
+type BIC string
+
+// ParseBIC accepts BICs in any case and returns them once their structure
+// and country are verified.
+func ParseBIC(value string) (BIC, error) {
+	bic := BIC(strings.ToUpper(strings.TrimSpace(value)))
+
+	if err := bic.Validate(); err != nil {
+		return "", err
+	}
+
+	return bic, nil
+}
+
+// Validate checks the BIC has 8 or 11 characters and a valid country code.
+func (b BIC) Validate() error {
+	if !bicRegexp.MatchString(string(b)) {
+		return fmt.Errorf("%w: %q should match '%s'", ErrInvalidBIC, string(b), bicRegexp)
+	}
+
+	if !IsCountryCode(b.CountryCode()) {
+		return fmt.Errorf("%w: unknown country %q", ErrInvalidBIC, b.CountryCode())
+	}
+
+	return nil
+}
+
+func (b BIC) String() string {
+	return string(b)
+}
+
+func (b BIC) part(start, end int) string {
+	if len(b) < end {
+		return ""
+	}
+
+	return string(b[start:end])
+}
+
+// BankCode returns the institution code.
+func (b BIC) BankCode() string {
+	return b.part(0, 4)
+}
+
+func (b BIC) CountryCode() string {
+	return b.part(4, 6)
+}
+
+func (b BIC) LocationCode() string {
+	return b.part(6, 8)
+}
+
+// BranchCode returns the branch code, XXX for the primary office.
+func (b BIC) BranchCode() string {
+	if len(b) == 8 {
+		return primaryOffice
+	}
+
+	return b.part(8, 11)
+}
+
+// Long returns the 11 character form of the BIC.
+func (b BIC) Long() BIC {
+	if len(b) == 8 {
+		return b + primaryOffice
+	}
+
+	return b
+}
+
+// IsTest reports whether the BIC belongs to a test and training
+// destination, whose location code ends in 0.
+func (b BIC) IsTest() bool {
+	return strings.HasSuffix(b.LocationCode(), "0")
+}
@@ -0,0 +1,297 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidIBAN = errors.New("invalid iban")
+
+type span struct {
+	start, end int
+}
+
+// ibanFormat describes the BBAN of a country, using the notation of the SWIFT
+// IBAN registry, and where the bank, branch and account number sit in it.
+type ibanFormat struct {
+	structure string
+	bank      span
+	branch    span
+	account   span
+
+	bban   *regexp.Regexp
+	length int
+}
+
+// ibanFormats lists every country of the SWIFT IBAN registry.
+var ibanFormats = map[string]*ibanFormat{
+	"AD": {structure: "4!n4!n12!c", bank: span{0, 4}, branch: span{4, 8}, account: span{8, 20}},
+	"AE": {structure: "3!n16!n", bank: span{0, 3}, account: span{3, 19}},
+	"AL": {structure: "8!n16!c", bank: span{0, 3}, branch: span{3, 7}, account: span{8, 24}},
+	"AT": {structure: "5!n11!n", bank: span{0, 5}, account: span{5, 16}},
+	"AZ": {structure: "4!a20!c", bank: span{0, 4}, account: span{4, 24}},
+	"BA": {structure: "3!n3!n8!n2!n", bank: span{0, 3}, branch: span{3, 6}, account: span{6, 14}},
+	"BE": {structure: "3!n7!n2!n", bank: span{0, 3}, account: span{3, 10}},
+	"BG": {structure: "4!a4!n2!n8!c", bank: span{0, 4}, branch: span{4, 8}, account: span{8, 18}},
+	"BH": {structure: "4!a14!c", bank: span{0, 4}, account: span{4, 18}},
+	"BI": {structure: "5!n5!n11!n2!n", bank: span{0, 5}, branch: span{5, 10}, account: span{10, 21}},
+	"BR": {structure: "8!n5!n10!n1!a1!c", bank: span{0, 8}, branch: span{8, 13}, account: span{13, 23}},
+	"BY": {structure: "4!c4!n16!c", bank: span{0, 4}, account: span{8, 24}},
+	"CH": {structure: "5!n12!c", bank: span{0, 5}, account: span{5, 17}},
+	"CR": {structure: "4!n14!n", bank: span{0, 4}, account: span{4, 18}},
+	"CY": {structure: "3!n5!n16!c", bank: span{0, 3}, branch: span{3, 8}, account: span{8, 24}},
+	"CZ": {structure: "4!n6!n10!n", bank: span{0, 4}, account: span{4, 20}},
+	"DE": {structure: "8!n10!n", bank: span{0, 8}, account: span{8, 18}},
+	"DJ": {structure: "5!n5!n11!n2!n", bank: span{0, 5}, branch: span{5, 10}, account: span{10, 21}},
+	"DK": {structure: "4!n9!n1!n", bank: span{0, 4}, account: span{4, 14}},
+	"DO": {structure: "4!c20!n", bank: span{0, 4}, account: span{4, 24}},
+	"EE": {structure: "2!n2!n11!n1!n", bank: span{0, 2}, account: span{2, 16}},
+	"EG": {structure: "4!n4!n17!n", bank: span{0, 4}, branch: span{4, 8}, account: span{8, 25}},
+	"ES": {structure: "4!n4!n1!n1!n10!n", bank: span{0, 4}, branch: span{4, 8}, account: span{10, 20}},
+	"FI": {structure: "3!n11!n", bank: span{0, 3}, account: span{3, 14}},
+	"FK": {structure: "2!a12!n", bank: span{0, 2}, account: span{2, 14}},
+	"FO": {structure: "4!n9!n1!n", bank: span{0, 4}, account: span{4, 14}},
+	"FR": {structure: "5!n5!n11!c2!n", bank: span{0, 5}, branch: span{5, 10}, account: span{10, 21}},
+	"GB": {structure: "4!a6!n8!n", bank: span{0, 4}, branch: span{4, 10}, account: span{10, 18}},
+	"GE": {structure: "2!a16!n", bank: span{0, 2}, account: span{2, 18}},
+	"GI": {structure: "4!a15!c", bank: span{0, 4}, account: span{4, 19}},
+	"GL": {structure: "4!n9!n1!n", bank: span{0, 4}, account: span{4, 14}},
+	"GR": {structure: "3!n4!n16!c", bank: span{0, 3}, branch: span{3, 7}, account: span{7, 23}},
+	"GT": {structure: "4!c20!c", bank: span{0, 4}, account: span{4, 24}},
+	"HN": {structure: "4!a20!n", bank: span{0, 4}, account: span{4, 24}},
+	"HR": {structure: "7!n10!n", bank: span{0, 7}, account: span{7, 17}},
+	"HU": {structure: "3!n4!n1!n15!n1!n", bank: span{0, 3}, branch: span{3, 7}, account: span{8, 23}},
+	"IE": {structure: "4!a6!n8!n", bank: span{0, 4}, branch: span{4, 10}, account: span{10, 18}},
+	"IL": {structure: "3!n3!n13!n", bank: span{0, 3}, branch: span{3, 6}, account: span{6, 19}},
+	"IQ": {structure: "4!a3!n12!n", bank: span{0, 4}, branch: span{4, 7}, account: span{7, 19}},
+	"IS": {structure: "4!n2!n6!n10!n", bank: span{0, 4}, account: span{6, 12}},
+	"IT": {structure: "1!a5!n5!n12!c", bank: span{1, 6}, branch: span{6, 11}, account: span{11, 23}},
+	"JO": {structure: "4!a4!n18!c", bank: span{0, 4}, branch: span{4, 8}, account: span{8, 26}},
+	"KW": {structure: "4!a22!c", bank: span{0, 4}, account: span{4, 26}},
+	"KZ": {structure: "3!n13!c", bank: span{0, 3}, account: span{3, 16}},
+	"LB": {structure: "4!n20!c", bank: span{0, 4}, account: span{4, 24}},
+	"LC": {structure: "4!a24!c", bank: span{0, 4}, account: span{4, 28}},
+	"LI": {structure: "5!n12!c", bank: span{0, 5}, account: span{5, 17}},
+	"LT": {structure: "5!n11!n", bank: span{0, 5}, account: span{5, 16}},
+	"LU": {structure: "3!n13!c", bank: span{0, 3}, account: span{3, 16}},
+	"LV": {structure: "4!a13!c", bank: span{0, 4}, account: span{4, 17}},
+	"LY": {structure: "3!n3!n15!n", bank: span{0, 3}, branch: span{3, 6}, account: span{6, 21}},
+	"MC": {structure: "5!n5!n11!c2!n", bank: span{0, 5}, branch: span{5, 10}, account: span{10, 21}},
+	"MD": {structure: "2!c18!c", bank: span{0, 2}, account: span{2, 20}},
+	"ME": {structure: "3!n13!n2!n", bank: span{0, 3}, account: span{3, 16}},
+	"MK": {structure: "3!n10!c2!n", bank: span{0, 3}, account: span{3, 13}},
+	"MN": {structure: "4!n12!n", bank: span{0, 4}, account: span{4, 16}},
+	"MR": {structure: "5!n5!n11!n2!n", bank: span{0, 5}, branch: span{5, 10}, account: span{10, 21}},
+	"MT": {structure: "4!a5!n18!c", bank: span{0, 4}, branch: span{4, 9}, account: span{9, 27}},
+	"MU": {structure: "4!a2!n2!n12!n3!n3!a", bank: span{0, 6}, branch: span{6, 8}, account: span{8, 20}},
+	"NI": {structure: "4!a20!n", bank: span{0, 4}, account: span{4, 24}},
+	"NL": {structure: "4!a10!n", bank: span{0, 4}, account: span{4, 14}},
+	"NO": {structure: "4!n6!n1!n", bank: span{0, 4}, account: span{4, 10}},
+	"OM": {structure: "3!n16!c", bank: span{0, 3}, account: span{3, 19}},
+	"PK": {structure: "4!a16!c", bank: span{0, 4}, account: span{4, 20}},
+	"PL": {structure: "8!n16!n", bank: span{0, 8}, account: span{8, 24}},
+	"PS": {structure: "4!a21!c", bank: span{0, 4}, account: span{4, 25}},
+	"PT": {structure: "4!n4!n11!n2!n", bank: span{0, 4}, branch: span{4, 8}, account: span{8, 19}},
+	"QA": {structure: "4!a21!c", bank: span{0, 4}, account: span{4, 25}},
+	"RO": {structure: "4!a16!c", bank: span{0, 4}, account: span{4, 20}},
+	"RS": {structure: "3!n13!n2!n", bank: span{0, 3}, account: span{3, 16}},
+	"RU": {structure: "9!n5!n15!c", bank: span{0, 9}, branch: span{9, 14}, account: span{14, 29}},
+	"SA": {structure: "2!n18!c", bank: span{0, 2}, account: span{2, 20}},
+	"SC": {structure: "4!a2!n2!n16!n3!a", bank: span{0, 6}, branch: span{6, 8}, account: span{8, 24}},
+	"SD": {structure: "2!n12!n", bank: span{0, 2}, account: span{2, 14}},
+	"SE": {structure: "3!n16!n1!n", bank: span{0, 3}, account: span{3, 20}},
+	"SI": {structure: "5!n8!n2!n", bank: span{0, 5}, account: span{5, 13}},
+	"SK": {structure: "4!n6!n10!n", bank: span{0, 4}, account: span{4, 20}},
+	"SM": {structure: "1!a5!n5!n12!c", bank: span{1, 6}, branch: span{6, 11}, account: span{11, 23}},
+	"SO": {structure: "4!n3!n12!n", bank: span{0, 4}, branch: span{4, 7}, account: span{7, 19}},
+	"ST": {structure: "4!n4!n11!n2!n", bank: span{0, 4}, branch: span{4, 8}, account: span{8, 19}},
+	"SV": {structure: "4!a20!n", bank: span{0, 4}, account: span{4, 24}},
+	"TL": {structure: "3!n14!n2!n", bank: span{0, 3}, account: span{3, 17}},
+	"TN": {structure: "2!n3!n13!n2!n", bank: span{0, 2}, branch: span{2, 5}, account: span{5, 18}},
+	"TR": {structure: "5!n1!n16!c", bank: span{0, 5}, account: span{6, 22}},
+	"UA": {structure: "6!n19!c", bank: span{0, 6}, account: span{6, 25}},
+	"VA": {structure: "3!n15!n", bank: span{0, 3}, account: span{3, 18}},
+	"VG": {structure: "4!a16!n", bank: span{0, 4}, account: span{4, 20}},
+	"XK": {structure: "4!n10!n2!n", bank: span{0, 2}, branch: span{2, 4}, account: span{4, 14}},
+	"YE": {structure: "4!a4!n18!c", bank: span{0, 4}, branch: span{4, 8}, account: span{8, 26}},
+}
+
+var (
+	structureRegexp = regexp.MustCompile(`(\d+)!([nac])`)
+	structureClass  = map[string]string{"n": "[0-9]", "a": "[A-Z]", "c": "[A-Z0-9]"}
+)
+
+// compile turns the registry notation, e.g. 4!a6!n8!n, into a regexp
+// matching the BBAN.
+func (f *ibanFormat) compile() {
+	pattern := "^"
+
+	for _, match := range structureRegexp.FindAllStringSubmatch(f.structure, -1) {
+		size, _ := strconv.Atoi(match[1])
+
+		pattern += fmt.Sprintf("%s{%d}", structureClass[match[2]], size)
+		f.length += size
+	}
+
+	f.bban = regexp.MustCompile(pattern + "$")
+	f.length += 4
+}
+
+func init() { // nolint: gochecknoinits
+	for _, format := range ibanFormats {
+		format.compile()
+	}
+}
+
+// IBAN is an International Bank Account Number, stored in its electronic
+// form, e.g. GB82WEST12345698765432.
+type IBAN string
+
+// ParseIBAN accepts IBANs in electronic or print form, in any case, and
+// returns them in electronic form once their structure and checksum are
+// verified.
+func ParseIBAN(value string) (IBAN, error) {
+	iban := IBAN(strings.ToUpper(strings.Join(strings.Fields(value), "")))
+
+	if err := iban.Validate(); err != nil {
+		return "", err
+	}
+
+	return iban, nil
+}
+
+// Validate checks the country specific length and structure, and the mod-97
+// checksum of the IBAN.
+func (i IBAN) Validate() error {
+	value := string(i)
+
+	if len(value) < 4 {
+		return fmt.Errorf("%w: %q is too short", ErrInvalidIBAN, value)
+	}
+
+	format, exists := ibanFormats[i.CountryCode()]
+	if !exists {
+		return fmt.Errorf("%w: country %q doesn't use IBANs", ErrInvalidIBAN, i.CountryCode())
+	}
+
+	if len(value) != format.length {
+		return fmt.Errorf("%w: %s IBANs have %d characters, got %d",
+			ErrInvalidIBAN, i.CountryCode(), format.length, len(value))
+	}
+
+	if !format.bban.MatchString(i.BBAN()) {
+		return fmt.Errorf("%w: %q doesn't match the %s structure %s",
+			ErrInvalidIBAN, value, i.CountryCode(), format.structure)
+	}
+
+	if !isDigits(i.CheckDigits()) || mod97(value[4:]+value[:4]) != 1 {
+		return fmt.Errorf("%w: checksum mismatch for %q", ErrInvalidIBAN, value)
+	}
+
+	return nil
+}
+
+// mod97 computes the ISO 7064 remainder of value, with letters expanded to
+// numbers, A being 10.
+func mod97(value string) int {
+	remainder := 0
+
+	for _, char := range value {
+		switch {
+		case '0' <= char && char <= '9':
+			remainder = (remainder*10 + int(char-'0')) % 97
+		case 'A' <= char && char <= 'Z':
+			remainder = (remainder*100 + int(char-'A') + 10) % 97
+		default:
+			return -1
+		}
+	}
+
+	return remainder
+}
+
+func isDigits(value string) bool {
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return value != ""
+}
+
+func (i IBAN) String() string {
+	return string(i)
+}
+
+// Print returns the print form of the IBAN, in groups of four characters.
+func (i IBAN) Print() string {
+	groups := make([]string, 0, len(i)/4+1)
+
+	for start := 0; start < len(i); start += 4 {
+		end := start + 4
+		if end > len(i) {
+			end = len(i)
+		}
+
+		groups = append(groups, string(i[start:end]))
+	}
+
+	return strings.Join(groups, " ")
+}
+
+func (i IBAN) part(start, end int) string {
+	if len(i) < end {
+		return ""
+	}
+
+	return string(i[start:end])
+}
+
+func (i IBAN) CountryCode() string {
+	return i.part(0, 2)
+}
+
+func (i IBAN) CheckDigits() string {
+	return i.part(2, 4)
+}
+
+// BBAN returns the Basic Bank Account Number, the country specific part of
+// the IBAN.
+func (i IBAN) BBAN() string {
+	if len(i) < 4 {
+		return ""
+	}
+
+	return string(i[4:])
+}
+
+func (i IBAN) component(pick func(*ibanFormat) span) string {
+	format, exists := ibanFormats[i.CountryCode()]
+	if !exists || len(i) != format.length {
+		return ""
+	}
+
+	s := pick(format)
+
+	return i.part(s.start+4, s.end+4)
+}
+
+// BankCode returns the bank identifier held in the BBAN, e.g. the sort code
+// prefix for GB or the BLZ for DE.
+func (i IBAN) BankCode() string {
+	return i.component(func(f *ibanFormat) span { return f.bank })
+}
+
+// BranchCode returns the branch identifier held in the BBAN, if the country
+// has one.
+func (i IBAN) BranchCode() string {
+	return i.component(func(f *ibanFormat) span { return f.branch })
+}
+
+func (i IBAN) AccountNumber() string {
+	return i.component(func(f *ibanFormat) span { return f.account })
+}
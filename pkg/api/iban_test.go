@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IBAN", func() {
+	DescribeTable("should accept valid IBANs",
+		func(value string, bank, branch, account string) {
+			iban, err := ParseIBAN(value)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(iban.BankCode()).To(Equal(bank))
+			Expect(iban.BranchCode()).To(Equal(branch))
+			Expect(iban.AccountNumber()).To(Equal(account))
+		},
+		Entry("GB", "GB82WEST12345698765432", "WEST", "123456", "98765432"),
+		Entry("DE", "DE89370400440532013000", "37040044", "", "0532013000"),
+		Entry("FR", "FR1420041010050500013M02606", "20041", "01005", "0500013M026"),
+		Entry("IT", "IT60X0542811101000000123456", "05428", "11101", "000000123456"),
+		Entry("ES", "ES9121000418450200051332", "2100", "0418", "0200051332"),
+		Entry("NL", "NL91ABNA0417164300", "ABNA", "", "0417164300"),
+		Entry("BE", "BE68539007547034", "539", "", "0075470"),
+		Entry("CH", "CH9300762011623852957", "00762", "", "011623852957"),
+		Entry("PL", "PL61109010140000071219812874", "10901014", "", "0000071219812874"),
+		Entry("NO", "NO9386011117947", "8601", "", "111794"),
+		Entry("MU", "MU17BOMM0101101030300200000MUR", "BOMM01", "01", "101030300200"),
+		Entry("IS", "IS140159260076545510730339", "0159", "", "007654"),
+		Entry("VA", "VA59001123000012345678", "001", "", "123000012345678"),
+		Entry("BY", "BY13NBRB3600900000002Z00AB00", "NBRB", "", "900000002Z00AB00"),
+		Entry("RU", "RU0304452522540817810538091310419", "044525225", "40817", "810538091310419"),
+		Entry("SC", "SC18SSCB11010000000000001497USD", "SSCB11", "01", "0000000000001497"),
+		Entry("IQ", "IQ98NBIQ850123456789012", "NBIQ", "850", "123456789012"),
+		Entry("LC", "LC55HEMM000100010012001200023015", "HEMM", "", "000100010012001200023015"),
+		Entry("ST", "ST23000100010051845310146", "0001", "0001", "00518453101"),
+		Entry("SV", "SV62CENR00000000000000700025", "CENR", "", "00000000000000700025"),
+		Entry("TL", "TL380080012345678910157", "008", "", "00123456789101"),
+		Entry("LY", "LY83002048000020100120361", "002", "048", "000020100120361"),
+		Entry("SD", "SD2129010501234001", "29", "", "010501234001"),
+		Entry("BI", "BI4210000100010000332045181", "10000", "10001", "00003320451"),
+		Entry("DJ", "DJ2100010000000154000100186", "00010", "00000", "01540001001"),
+		Entry("MN", "MN121234123456789123", "1234", "", "123456789123"),
+		Entry("NI", "NI45BAPR00000013000003558124", "BAPR", "", "00000013000003558124"),
+		Entry("OM", "OM810180000001299123456", "018", "", "0000001299123456"),
+		Entry("SO", "SO211000001001000100141", "1000", "001", "001000100141"),
+		Entry("FK", "FK88SC123456789012", "SC", "", "123456789012"),
+		Entry("YE", "YE15CBYE0001018861234567891234", "CBYE", "0001", "018861234567891234"),
+	)
+
+	It("should normalise the print form", func() {
+		iban, err := ParseIBAN("gb82 west 1234 5698 7654 32")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(iban).To(Equal(IBAN("GB82WEST12345698765432")))
+		Expect(iban.Print()).To(Equal("GB82 WEST 1234 5698 7654 32"))
+		Expect(iban.CountryCode()).To(Equal("GB"))
+		Expect(iban.CheckDigits()).To(Equal("82"))
+		Expect(iban.BBAN()).To(Equal("WEST12345698765432"))
+	})
+
+	DescribeTable("should reject invalid IBANs",
+		func(value, message string) {
+			_, err := ParseIBAN(value)
+
+			Expect(errors.Is(err, ErrInvalidIBAN)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("too short", "GB8", "too short"),
+		Entry("unknown country", "US64SVBKUS6S3300958879", "doesn't use IBANs"),
+		Entry("wrong length", "GB82WEST1234569876543", "have 22 characters, got 21"),
+		Entry("wrong structure", "GB82WEST12345698765A32", "doesn't match the GB structure 4!a6!n8!n"),
+		Entry("wrong checksum", "GB83WEST12345698765432", "checksum mismatch"),
+		Entry("letters as check digits", "GBAAWEST12345698765432", "checksum mismatch"),
+	)
+
+	It("should marshal as a plain string", func() {
+		attributes := AccountAttributes{IBAN: "GB82WEST12345698765432", BIC: "NWBKGB22"}
+
+		body, err := json.Marshal(attributes)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(body).To(MatchJSON(`{
+			"country": "",
+			"iban": "GB82WEST12345698765432",
+			"bic": "NWBKGB22",
+			"account_classification": ""
+		}`))
+
+		var decoded AccountAttributes
+		Expect(json.Unmarshal(body, &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(attributes))
+	})
+})
+
+var _ = Describe("BIC", func() {
+	It("should parse 8 and 11 character BICs", func() {
+		bic, err := ParseBIC(" nwbkgb22 ")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(bic).To(Equal(BIC("NWBKGB22")))
+		Expect(bic.BankCode()).To(Equal("NWBK"))
+		Expect(bic.CountryCode()).To(Equal("GB"))
+		Expect(bic.LocationCode()).To(Equal("22"))
+		Expect(bic.BranchCode()).To(Equal("XXX"))
+		Expect(bic.Long()).To(Equal(BIC("NWBKGB22XXX")))
+
+		bic, err = ParseBIC("DEUTDEFF500")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(bic.BranchCode()).To(Equal("500"))
+		Expect(bic.Long()).To(Equal(bic))
+		Expect(bic.IsTest()).To(BeFalse())
+		Expect(BIC("NWBKGB20").IsTest()).To(BeTrue())
+	})
+
+	It("should reject invalid BICs", func() {
+		for _, value := range []string{"NWBK", "NWBKGB2", "NWBKGB22X", "NWBKZZ22"} {
+			_, err := ParseBIC(value)
+			Expect(errors.Is(err, ErrInvalidBIC)).To(BeTrue(), value)
+		}
+	})
+})
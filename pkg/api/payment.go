@@ -0,0 +1,120 @@
+package api
+
+type PaymentScheme string
+
+const (
+	PaymentSchemeFPS   PaymentScheme = "FPS"
+	PaymentSchemeBACS  PaymentScheme = "BACS"
+	PaymentSchemeCHAPS PaymentScheme = "CHAPS"
+	PaymentSchemeSEPA  PaymentScheme = "SEPACT"
+)
+
+type ChargeBearer string
+
+const (
+	ChargeBearerShared ChargeBearer = "SHAR"
+	ChargeBearerSender ChargeBearer = "DEBT"
+	ChargeBearerPayee  ChargeBearer = "CRED"
+)
+
+// PaymentParty describes the beneficiary or the debtor of a payment.
+type PaymentParty struct {
+	AccountName       string   `json:"account_name,omitempty"`
+	AccountNumber     string   `json:"account_number,omitempty"`
+	AccountNumberCode string   `json:"account_number_code,omitempty"`
+	AccountType       *int     `json:"account_type,omitempty"`
+	Address           []string `json:"address,omitempty"`
+	BankID            string   `json:"bank_id,omitempty"`
+	BankIDCode        string   `json:"bank_id_code,omitempty"`
+	Name              string   `json:"name,omitempty"`
+	Country           string   `json:"country,omitempty"`
+}
+
+type SponsorParty struct {
+	AccountNumber string `json:"account_number,omitempty"`
+	BankID        string `json:"bank_id,omitempty"`
+	BankIDCode    string `json:"bank_id_code,omitempty"`
+}
+
+// Charge is an amount charged for processing a payment.
+type Charge struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type ChargesInformation struct {
+	BearerCode              ChargeBearer `json:"bearer_code,omitempty"`
+	SenderCharges           []Charge     `json:"sender_charges,omitempty"`
+	ReceiverChargesAmount   string       `json:"receiver_charges_amount,omitempty"`
+	ReceiverChargesCurrency string       `json:"receiver_charges_currency,omitempty"`
+}
+
+type ForeignExchange struct {
+	ContractReference string `json:"contract_reference,omitempty"`
+	ExchangeRate      string `json:"exchange_rate,omitempty"`
+	OriginalAmount    string `json:"original_amount,omitempty"`
+	OriginalCurrency  string `json:"original_currency,omitempty"`
+}
+
+// PaymentAttributes models the attributes of the payments API. Amounts are
+// decimal strings, as sent by the API, so they don't lose precision.
+type PaymentAttributes struct {
+	Amount               string              `json:"amount"`
+	Currency             string              `json:"currency"`
+	BeneficiaryParty     *PaymentParty       `json:"beneficiary_party,omitempty"`
+	DebtorParty          *PaymentParty       `json:"debtor_party,omitempty"`
+	SponsorParty         *SponsorParty       `json:"sponsor_party,omitempty"`
+	ChargesInformation   *ChargesInformation `json:"charges_information,omitempty"`
+	FX                   *ForeignExchange    `json:"fx,omitempty"`
+	EndToEndReference    string              `json:"end_to_end_reference,omitempty"`
+	NumericReference     string              `json:"numeric_reference,omitempty"`
+	PaymentID            string              `json:"payment_id,omitempty"`
+	PaymentPurpose       string              `json:"payment_purpose,omitempty"`
+	PaymentScheme        PaymentScheme       `json:"payment_scheme,omitempty"`
+	PaymentType          string              `json:"payment_type,omitempty"`
+	ProcessingDate       string              `json:"processing_date,omitempty"`
+	Reference            string              `json:"reference,omitempty"`
+	SchemePaymentSubType string              `json:"scheme_payment_sub_type,omitempty"`
+	SchemePaymentType    string              `json:"scheme_payment_type,omitempty"`
+}
+
+type Payment struct {
+	OrganisationResource
+
+	Attributes PaymentAttributes `json:"attributes"`
+}
+
+func (p Payment) GetID() string { // nolint: gocritic
+	return p.ID
+}
+
+func (p Payment) GetVersion() int { // nolint: gocritic
+	return p.Version
+}
+
+func NewPayment(id string, version int) *Payment {
+	return &Payment{
+		OrganisationResource: OrganisationResource{
+			Resource: Resource{
+				ID:      id,
+				Version: version,
+			},
+		},
+	}
+}
+
+type PaymentList struct {
+	Items []Payment
+}
+
+func (p PaymentList) GetID() string {
+	return ""
+}
+
+func (p PaymentList) GetVersion() int {
+	return 0
+}
+
+func (p PaymentList) GetItems() []Payment {
+	return p.Items
+}
@@ -0,0 +1,271 @@
+package api
+
+type PaymentRelationships struct {
+	Payment *Relationship `json:"payment,omitempty"`
+}
+
+// PaymentChild is embedded by the resources nested under a payment, which
+// reference it through their relationships.
+type PaymentChild struct {
+	Relationships *PaymentRelationships `json:"relationships,omitempty"`
+}
+
+func newPaymentChild(paymentID string) PaymentChild {
+	return PaymentChild{
+		Relationships: &PaymentRelationships{
+			Payment: &Relationship{
+				Data: []RelationshipReference{{Type: "payments", ID: paymentID}},
+			},
+		},
+	}
+}
+
+func (c PaymentChild) GetParentID() string {
+	if c.Relationships == nil || c.Relationships.Payment == nil || len(c.Relationships.Payment.Data) == 0 {
+		return ""
+	}
+
+	return c.Relationships.Payment.Data[0].ID
+}
+
+func (c *PaymentChild) SetParentID(paymentID string) {
+	*c = newPaymentChild(paymentID)
+}
+
+type PaymentSubmissionAttributes struct {
+	Status                  string `json:"status,omitempty"`
+	StatusReason            string `json:"status_reason,omitempty"`
+	SchemeStatusCode        string `json:"scheme_status_code,omitempty"`
+	SubmissionDatetime      string `json:"submission_datetime,omitempty"`
+	SettlementDate          string `json:"settlement_date,omitempty"`
+	SettlementCycle         int    `json:"settlement_cycle,omitempty"`
+	RedirectedBankID        string `json:"redirected_bank_id,omitempty"`
+	RedirectedAccountNumber string `json:"redirected_account_number,omitempty"`
+}
+
+// PaymentSubmission sends a payment to its scheme.
+type PaymentSubmission struct {
+	OrganisationResource
+	PaymentChild
+
+	Attributes PaymentSubmissionAttributes `json:"attributes"`
+}
+
+func (p PaymentSubmission) GetID() string { // nolint: gocritic
+	return p.ID
+}
+
+func (p PaymentSubmission) GetVersion() int { // nolint: gocritic
+	return p.Version
+}
+
+func NewPaymentSubmission(paymentID, id string, version int) *PaymentSubmission {
+	return &PaymentSubmission{
+		OrganisationResource: OrganisationResource{
+			Resource: Resource{
+				ID:      id,
+				Version: version,
+			},
+		},
+		PaymentChild: newPaymentChild(paymentID),
+	}
+}
+
+type PaymentSubmissionList struct {
+	PaymentID string
+	Items     []PaymentSubmission
+}
+
+func (p PaymentSubmissionList) GetID() string {
+	return ""
+}
+
+func (p PaymentSubmissionList) GetVersion() int {
+	return 0
+}
+
+func (p PaymentSubmissionList) GetParentID() string {
+	return p.PaymentID
+}
+
+func (p *PaymentSubmissionList) SetParentID(paymentID string) {
+	p.PaymentID = paymentID
+}
+
+func (p PaymentSubmissionList) GetItems() []PaymentSubmission {
+	return p.Items
+}
+
+type PaymentReturnAttributes struct {
+	ReturnCode string `json:"return_code,omitempty"`
+	Amount     string `json:"amount,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+}
+
+// PaymentReturn sends back a payment that was received.
+type PaymentReturn struct {
+	OrganisationResource
+	PaymentChild
+
+	Attributes PaymentReturnAttributes `json:"attributes"`
+}
+
+func (p PaymentReturn) GetID() string { // nolint: gocritic
+	return p.ID
+}
+
+func (p PaymentReturn) GetVersion() int { // nolint: gocritic
+	return p.Version
+}
+
+func NewPaymentReturn(paymentID, id string, version int) *PaymentReturn {
+	return &PaymentReturn{
+		OrganisationResource: OrganisationResource{
+			Resource: Resource{
+				ID:      id,
+				Version: version,
+			},
+		},
+		PaymentChild: newPaymentChild(paymentID),
+	}
+}
+
+type PaymentReturnList struct {
+	PaymentID string
+	Items     []PaymentReturn
+}
+
+func (p PaymentReturnList) GetID() string {
+	return ""
+}
+
+func (p PaymentReturnList) GetVersion() int {
+	return 0
+}
+
+func (p PaymentReturnList) GetParentID() string {
+	return p.PaymentID
+}
+
+func (p *PaymentReturnList) SetParentID(paymentID string) {
+	p.PaymentID = paymentID
+}
+
+func (p PaymentReturnList) GetItems() []PaymentReturn {
+	return p.Items
+}
+
+// PaymentReversalAttributes is empty, reversals only reference the payment
+// they reverse.
+type PaymentReversalAttributes struct{}
+
+// PaymentReversal cancels a payment that was sent.
+type PaymentReversal struct {
+	OrganisationResource
+	PaymentChild
+
+	Attributes PaymentReversalAttributes `json:"attributes"`
+}
+
+func (p PaymentReversal) GetID() string { // nolint: gocritic
+	return p.ID
+}
+
+func (p PaymentReversal) GetVersion() int { // nolint: gocritic
+	return p.Version
+}
+
+func NewPaymentReversal(paymentID, id string, version int) *PaymentReversal {
+	return &PaymentReversal{
+		OrganisationResource: OrganisationResource{
+			Resource: Resource{
+				ID:      id,
+				Version: version,
+			},
+		},
+		PaymentChild: newPaymentChild(paymentID),
+	}
+}
+
+type PaymentReversalList struct {
+	PaymentID string
+	Items     []PaymentReversal
+}
+
+func (p PaymentReversalList) GetID() string {
+	return ""
+}
+
+func (p PaymentReversalList) GetVersion() int {
+	return 0
+}
+
+func (p PaymentReversalList) GetParentID() string {
+	return p.PaymentID
+}
+
+func (p *PaymentReversalList) SetParentID(paymentID string) {
+	p.PaymentID = paymentID
+}
+
+func (p PaymentReversalList) GetItems() []PaymentReversal {
+	return p.Items
+}
+
+type PaymentRecallAttributes struct {
+	Reason     string `json:"reason,omitempty"`
+	ReasonCode string `json:"reason_code,omitempty"`
+}
+
+// PaymentRecall asks the beneficiary bank to send back a payment.
+type PaymentRecall struct {
+	OrganisationResource
+	PaymentChild
+
+	Attributes PaymentRecallAttributes `json:"attributes"`
+}
+
+func (p PaymentRecall) GetID() string { // nolint: gocritic
+	return p.ID
+}
+
+func (p PaymentRecall) GetVersion() int { // nolint: gocritic
+	return p.Version
+}
+
+func NewPaymentRecall(paymentID, id string, version int) *PaymentRecall {
+	return &PaymentRecall{
+		OrganisationResource: OrganisationResource{
+			Resource: Resource{
+				ID:      id,
+				Version: version,
+			},
+		},
+		PaymentChild: newPaymentChild(paymentID),
+	}
+}
+
+type PaymentRecallList struct {
+	PaymentID string
+	Items     []PaymentRecall
+}
+
+func (p PaymentRecallList) GetID() string {
+	return ""
+}
+
+func (p PaymentRecallList) GetVersion() int {
+	return 0
+}
+
+func (p PaymentRecallList) GetParentID() string {
+	return p.PaymentID
+}
+
+func (p *PaymentRecallList) SetParentID(paymentID string) {
+	p.PaymentID = paymentID
+}
+
+func (p PaymentRecallList) GetItems() []PaymentRecall {
+	return p.Items
+}
@@ -3,12 +3,28 @@ package api
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 )
 
+// Child is implemented by resources nested under a parent resource, e.g.
+// payment submissions, and by their lists.
+type Child interface {
+	GetParentID() string
+}
+
+// ParentSetter is implemented by pointers to Child resources, so they can be
+// nested under a parent without knowing their type.
+type ParentSetter interface {
+	SetParentID(id string)
+}
+
 type Scheme struct {
 	objToType    map[string]reflect.Type
 	typeToObj    map[reflect.Type]string
 	objEndpoints map[string]string
+	objParents   map[string]string
+	objFilters   map[string]map[string]bool
 }
 
 func NewScheme() *Scheme {
@@ -16,6 +32,8 @@ func NewScheme() *Scheme {
 		objToType:    map[string]reflect.Type{},
 		typeToObj:    map[reflect.Type]string{},
 		objEndpoints: map[string]string{},
+		objParents:   map[string]string{},
+		objFilters:   map[string]map[string]bool{},
 	}
 }
 
@@ -41,7 +59,64 @@ func (s *Scheme) Register(obj Object, endpoint string) {
 	s.objEndpoints[typeName] = endpoint
 }
 
-var missingObjTypeFmt = "missing type %s from scheme"
+// RegisterChild registers obj under parent, which must already be
+// registered. The endpoint is relative to the one of parent, e.g.
+// "submissions/%s" under "transaction/payments/%s".
+func (s *Scheme) RegisterChild(obj, parent Object, endpoint string) {
+	parentName := realTypeOf(parent).String()
+
+	parentEndpoint, exists := s.objEndpoints[parentName]
+	if !exists {
+		panic(fmt.Sprintf(missingObjTypeFmt, parentName))
+	}
+
+	s.Register(obj, fmt.Sprintf("%s/%s", parentEndpoint, endpoint))
+	s.objParents[reflect.TypeOf(obj).String()] = parentName
+}
+
+// ParentOf returns the type name of the parent obj is nested under, if any.
+func (s *Scheme) ParentOf(obj interface{}) (string, bool) {
+	parent, exists := s.objParents[realTypeOf(obj).String()]
+
+	return parent, exists
+}
+
+// RegisterFilters declares the filters the API accepts when listing obj,
+// e.g. bank_id for AccountList.
+func (s *Scheme) RegisterFilters(obj Object, names ...string) {
+	typeName := reflect.TypeOf(obj).String()
+
+	if s.objFilters[typeName] == nil {
+		s.objFilters[typeName] = map[string]bool{}
+	}
+
+	for _, name := range names {
+		s.objFilters[typeName][name] = true
+	}
+}
+
+// HasFilter reports whether the API accepts the named filter when listing
+// obj.
+func (s *Scheme) HasFilter(obj interface{}, name string) bool {
+	return s.objFilters[realTypeOf(obj).String()][name]
+}
+
+var (
+	missingObjTypeFmt = "missing type %s from scheme"
+	missingParentFmt  = "missing parent %s of %s"
+)
+
+// Kinds returns the names of the registered types, sorted.
+func (s *Scheme) Kinds() []string {
+	kinds := make([]string, 0, len(s.objToType))
+	for kind := range s.objToType {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	return kinds
+}
 
 func (s *Scheme) NewObj(kind string) (Object, error) {
 	reflectType, exists := s.objToType[kind]
@@ -80,9 +155,85 @@ func (s *Scheme) GetEndpointForObj(obj interface{}) (string, error) {
 	return endpoint, nil
 }
 
+// endpointArgs returns the parent ID to substitute in the endpoint of obj, if
+// obj is nested.
+func (s *Scheme) endpointArgs(obj Object) ([]interface{}, error) {
+	parent, exists := s.ParentOf(obj)
+	if !exists {
+		return nil, nil
+	}
+
+	child, ok := obj.(Child)
+	if !ok || child.GetParentID() == "" {
+		return nil, fmt.Errorf(missingParentFmt, parent, s.TypeName(obj))
+	}
+
+	return []interface{}{child.GetParentID()}, nil
+}
+
+// ObjectPath resolves the endpoint of obj, relative to the API version,
+// substituting its parent ID and then its own ID.
+func (s *Scheme) ObjectPath(obj Object) (string, error) {
+	endpoint, err := s.GetEndpointForObj(obj)
+	if err != nil {
+		return "", err
+	}
+
+	args, err := s.endpointArgs(obj)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Count(endpoint, "%s") > len(args) {
+		args = append(args, obj.GetID())
+	}
+
+	return fmt.Sprintf(endpoint, args...), nil
+}
+
+// CollectionPath resolves the endpoint objects like obj are created at.
+func (s *Scheme) CollectionPath(obj Object) (string, error) {
+	endpoint, err := s.GetEndpointForObj(obj)
+	if err != nil {
+		return "", err
+	}
+
+	args, err := s.endpointArgs(obj)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Count(endpoint, "%s") > len(args) {
+		endpoint = strings.TrimSuffix(endpoint, "/%s")
+	}
+
+	return fmt.Sprintf(endpoint, args...), nil
+}
+
 var Schema = NewScheme()
 
 func init() { // nolint: gochecknoinits
 	Schema.Register(Account{}, "organisation/accounts/%s")
 	Schema.Register(AccountList{}, "organisation/accounts")
+	Schema.Register(Payment{}, "transaction/payments/%s")
+	Schema.Register(PaymentList{}, "transaction/payments")
+
+	Schema.RegisterChild(PaymentSubmission{}, Payment{}, "submissions/%s")
+	Schema.RegisterChild(PaymentSubmissionList{}, Payment{}, "submissions")
+	Schema.RegisterChild(PaymentReturn{}, Payment{}, "returns/%s")
+	Schema.RegisterChild(PaymentReturnList{}, Payment{}, "returns")
+	Schema.RegisterChild(PaymentReversal{}, Payment{}, "reversals/%s")
+	Schema.RegisterChild(PaymentReversalList{}, Payment{}, "reversals")
+	Schema.RegisterChild(PaymentRecall{}, Payment{}, "recalls/%s")
+	Schema.RegisterChild(PaymentRecallList{}, Payment{}, "recalls")
+
+	Schema.Register(Subscription{}, "notification/subscriptions/%s")
+	Schema.Register(SubscriptionList{}, "notification/subscriptions")
+
+	Schema.RegisterFilters(AccountList{},
+		"bank_id_code", "bank_id", "account_number", "iban", "customer_id", "country", "status")
+	Schema.RegisterFilters(PaymentList{},
+		"currency", "payment_scheme", "payment_type", "reference", "end_to_end_reference", "processing_date")
+	Schema.RegisterFilters(PaymentSubmissionList{}, "status")
+	Schema.RegisterFilters(SubscriptionList{}, "record_type", "event_type", "callback_transport", "deactivated")
 }
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	paymentID    = "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
+	submissionID = "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5"
+)
+
+var _ = Describe("Scheme", func() {
+	DescribeTable("should resolve object and collection paths",
+		func(obj Object, objectPath, collectionPath string) {
+			path, err := Schema.ObjectPath(obj)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(path).To(Equal(objectPath))
+
+			path, err = Schema.CollectionPath(obj)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(path).To(Equal(collectionPath))
+		},
+		Entry("accounts", NewAccount(paymentID, 0),
+			"organisation/accounts/"+paymentID, "organisation/accounts"),
+		Entry("account lists", &AccountList{},
+			"organisation/accounts", "organisation/accounts"),
+		Entry("submissions", NewPaymentSubmission(paymentID, submissionID, 0),
+			"transaction/payments/"+paymentID+"/submissions/"+submissionID,
+			"transaction/payments/"+paymentID+"/submissions"),
+		Entry("submission lists", &PaymentSubmissionList{PaymentID: paymentID},
+			"transaction/payments/"+paymentID+"/submissions",
+			"transaction/payments/"+paymentID+"/submissions"),
+		Entry("recalls", *NewPaymentRecall(paymentID, submissionID, 0),
+			"transaction/payments/"+paymentID+"/recalls/"+submissionID,
+			"transaction/payments/"+paymentID+"/recalls"),
+	)
+
+	It("should know the parent of nested resources", func() {
+		parent, exists := Schema.ParentOf(&PaymentReturn{})
+		Expect(exists).To(BeTrue())
+		Expect(parent).To(Equal("api.Payment"))
+
+		_, exists = Schema.ParentOf(&Payment{})
+		Expect(exists).To(BeFalse())
+	})
+
+	It("should require the parent ID of nested resources", func() {
+		_, err := Schema.ObjectPath(&PaymentReversal{})
+		Expect(err).To(MatchError("missing parent api.Payment of api.PaymentReversal"))
+
+		_, err = Schema.CollectionPath(&PaymentReversalList{})
+		Expect(err).To(MatchError("missing parent api.Payment of api.PaymentReversalList"))
+	})
+
+	It("should list the registered kinds", func() {
+		Expect(Schema.Kinds()).To(ContainElements("api.Account", "api.AccountList", "api.PaymentRecall"))
+		Expect(sort.StringsAreSorted(Schema.Kinds())).To(BeTrue())
+	})
+
+	It("should nest resources under a parent ID", func() {
+		var setter ParentSetter = &PaymentRecall{}
+		setter.SetParentID(paymentID)
+		Expect(setter.(Child).GetParentID()).To(Equal(paymentID))
+
+		setter = &PaymentRecallList{}
+		setter.SetParentID(paymentID)
+		Expect(setter.(Child).GetParentID()).To(Equal(paymentID))
+	})
+
+	It("should refuse parents missing from the scheme", func() {
+		scheme := NewScheme()
+
+		Expect(func() {
+			scheme.RegisterChild(PaymentSubmission{}, Payment{}, "submissions/%s")
+		}).To(Panic())
+	})
+})
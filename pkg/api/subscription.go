@@ -0,0 +1,114 @@
+package api
+
+import "net/url"
+
+type CallbackTransport string
+
+const (
+	CallbackTransportHTTP  CallbackTransport = "http"
+	CallbackTransportQueue CallbackTransport = "queue"
+)
+
+// Event types notifications are sent for.
+const (
+	EventTypeCreated = "created"
+	EventTypeUpdated = "updated"
+	EventTypeDeleted = "deleted"
+)
+
+const subscriptionType = "subscriptions"
+
+// SubscriptionAttributes selects the notifications sent to the callback, by
+// record type, e.g. accounts, and event type.
+type SubscriptionAttributes struct {
+	CallbackTransport CallbackTransport `json:"callback_transport"`
+	CallbackURI       string            `json:"callback_uri"`
+	UserID            string            `json:"user_id,omitempty"`
+	RecordType        string            `json:"record_type"`
+	EventType         string            `json:"event_type"`
+	Deactivated       *bool             `json:"deactivated,omitempty"`
+}
+
+type Subscription struct {
+	OrganisationResource
+
+	Attributes SubscriptionAttributes `json:"attributes"`
+}
+
+func (s Subscription) GetID() string { // nolint: gocritic
+	return s.ID
+}
+
+func (s Subscription) GetVersion() int { // nolint: gocritic
+	return s.Version
+}
+
+// Validate checks the identifiers and the callback of the subscription.
+func (s Subscription) Validate() error { // nolint: gocritic
+	errs := ValidationErrors{}
+
+	if !IsUUID(s.ID) {
+		errs.add("id", s.ID, "must be a valid uuid")
+	}
+
+	if !IsUUID(s.OrganisationID) {
+		errs.add("organisation_id", s.OrganisationID, "must be a valid uuid")
+	}
+
+	if s.Type != subscriptionType {
+		errs.add("type", s.Type, "should be one of [%s]", subscriptionType)
+	}
+
+	attributes := s.Attributes
+
+	switch attributes.CallbackTransport {
+	case CallbackTransportHTTP:
+		if uri, err := url.Parse(attributes.CallbackURI); err != nil || uri.Scheme == "" || uri.Host == "" {
+			errs.add("attributes.callback_uri", attributes.CallbackURI, "must be an absolute url")
+		}
+	case CallbackTransportQueue:
+		if attributes.CallbackURI == "" {
+			errs.add("attributes.callback_uri", attributes.CallbackURI, "is required")
+		}
+	default:
+		errs.add("attributes.callback_transport", attributes.CallbackTransport,
+			"should be one of [%s %s]", CallbackTransportHTTP, CallbackTransportQueue)
+	}
+
+	if attributes.RecordType == "" {
+		errs.add("attributes.record_type", attributes.RecordType, "is required")
+	}
+
+	if attributes.EventType == "" {
+		errs.add("attributes.event_type", attributes.EventType, "is required")
+	}
+
+	return errs.err()
+}
+
+func NewSubscription(id string, version int) *Subscription {
+	return &Subscription{
+		OrganisationResource: OrganisationResource{
+			Resource: Resource{
+				ID:      id,
+				Version: version,
+			},
+		},
+	}
+}
+
+type SubscriptionList struct {
+	Items []Subscription
+}
+
+func (s SubscriptionList) GetID() string {
+	return ""
+}
+
+func (s SubscriptionList) GetVersion() int {
+	return 0
+}
+
+func (s SubscriptionList) GetItems() []Subscription {
+	return s.Items
+}
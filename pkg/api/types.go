@@ -1,15 +1,27 @@
 package api
 
+import "time"
+
 type Resource struct {
 	Type    string `json:"type"`
 	ID      string `json:"id"`
 	Version int    `json:"version"`
 }
 
+func (r *Resource) SetID(id string) {
+	r.ID = id
+}
+
+func (r *Resource) SetVersion(version int) {
+	r.Version = version
+}
+
 type OrganisationResource struct {
 	Resource
 
-	OrganisationID string `json:"organisation_id"`
+	OrganisationID string     `json:"organisation_id"`
+	CreatedOn      *time.Time `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time `json:"modified_on,omitempty"`
 }
 
 type AccountClassification string
@@ -19,26 +31,85 @@ const (
 	AccountClassificationBusiness AccountClassification = "Business"
 )
 
+type AccountStatus string
+
+const (
+	AccountStatusPending   AccountStatus = "pending"
+	AccountStatusConfirmed AccountStatus = "confirmed"
+	AccountStatusFailed    AccountStatus = "failed"
+)
+
+type PrivateIdentification struct {
+	BirthDate      string   `json:"birth_date,omitempty"`
+	BirthCountry   string   `json:"birth_country,omitempty"`
+	Identification string   `json:"identification,omitempty"`
+	Address        []string `json:"address,omitempty"`
+	City           string   `json:"city,omitempty"`
+	Country        string   `json:"country,omitempty"`
+}
+
+type OrganisationActor struct {
+	Name      []string `json:"name,omitempty"`
+	BirthDate string   `json:"birth_date,omitempty"`
+	Residency string   `json:"residency,omitempty"`
+}
+
+type OrganisationIdentification struct {
+	Identification string              `json:"identification,omitempty"`
+	Actors         []OrganisationActor `json:"actors,omitempty"`
+	Address        []string            `json:"address,omitempty"`
+	City           string              `json:"city,omitempty"`
+	Country        string              `json:"country,omitempty"`
+}
+
+// AccountAttributes models every attribute of the accounts API. Optional
+// attributes are omitted when empty, so a fetched account is sent back
+// exactly as it was received.
 type AccountAttributes struct {
-	Country                 string                `json:"country"`
-	BaseCurrency            string                `json:"base_currency"`
-	AccountNumber           string                `json:"account_number"`
-	BankID                  string                `json:"bank_id"`
-	BankIDCode              string                `json:"bank_id_code"`
-	BIC                     string                `json:"bic"`
-	IBAN                    string                `json:"iban"`
-	Name                    []string              `json:"name"`
-	AlternativeNames        []string              `json:"alternative_names"`
-	AccountClassification   AccountClassification `json:"account_classification"`
-	SecondaryIdentification string                `json:"secondary_identification"`
-	Switched                bool                  `json:"switched"`
-	Status                  string                `json:"status"`
+	Country                    string                      `json:"country"`
+	BaseCurrency               string                      `json:"base_currency,omitempty"`
+	BankID                     string                      `json:"bank_id,omitempty"`
+	BankIDCode                 string                      `json:"bank_id_code,omitempty"`
+	AccountNumber              string                      `json:"account_number,omitempty"`
+	CustomerID                 string                      `json:"customer_id,omitempty"`
+	IBAN                       IBAN                        `json:"iban,omitempty"`
+	BIC                        BIC                         `json:"bic,omitempty"`
+	Name                       []string                    `json:"name,omitempty"`
+	AlternativeNames           []string                    `json:"alternative_names,omitempty"`
+	AccountClassification      AccountClassification       `json:"account_classification"`
+	JointAccount               *bool                       `json:"joint_account,omitempty"`
+	AccountMatchingOptOut      *bool                       `json:"account_matching_opt_out,omitempty"`
+	SecondaryIdentification    string                      `json:"secondary_identification,omitempty"`
+	Switched                   *bool                       `json:"switched,omitempty"`
+	Status                     AccountStatus               `json:"status,omitempty"`
+	StatusReason               string                      `json:"status_reason,omitempty"`
+	ProcessingService          string                      `json:"processing_service,omitempty"`
+	UserDefinedInformation     string                      `json:"user_defined_information,omitempty"`
+	ValidationType             string                      `json:"validation_type,omitempty"`
+	ReferenceMask              string                      `json:"reference_mask,omitempty"`
+	AcceptanceQualifier        string                      `json:"acceptance_qualifier,omitempty"`
+	PrivateIdentification      *PrivateIdentification      `json:"private_identification,omitempty"`
+	OrganisationIdentification *OrganisationIdentification `json:"organisation_identification,omitempty"`
+}
+
+type RelationshipReference struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type Relationship struct {
+	Data []RelationshipReference `json:"data"`
+}
+
+type AccountRelationships struct {
+	MasterAccount *Relationship `json:"master_account,omitempty"`
 }
 
 type Account struct {
 	OrganisationResource
 
-	Attributes AccountAttributes `json:"attributes"`
+	Attributes    AccountAttributes     `json:"attributes"`
+	Relationships *AccountRelationships `json:"relationships,omitempty"`
 }
 
 func (a Account) GetID() string { // nolint: gocritic
@@ -71,3 +142,7 @@ func (a AccountList) GetID() string {
 func (a AccountList) GetVersion() int {
 	return 0
 }
+
+func (a AccountList) GetItems() []Account {
+	return a.Items
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func readData(path string) json.RawMessage {
+	content, err := ioutil.ReadFile(path)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	Expect(json.Unmarshal(content, &envelope)).To(Succeed())
+
+	return envelope.Data
+}
+
+var _ = Describe("Account", func() {
+	It("should re-serialise a full account byte for byte", func() {
+		data := readData("testdata/account_full.json")
+
+		account := &Account{}
+		Expect(json.Unmarshal(data, account)).To(Succeed())
+
+		Expect(account.CreatedOn.Equal(time.Date(2021, 3, 11, 12, 42, 20, 283000000, time.UTC))).To(BeTrue())
+		Expect(account.ModifiedOn.Equal(time.Date(2021, 3, 12, 8, 15, 2, 500000000, time.UTC))).To(BeTrue())
+		Expect(account.Attributes.CustomerID).To(Equal("234"))
+		Expect(*account.Attributes.JointAccount).To(BeFalse())
+		Expect(*account.Attributes.Switched).To(BeTrue())
+		Expect(account.Validate()).To(Succeed())
+		Expect(account.Attributes.PrivateIdentification.BirthCountry).To(Equal("GB"))
+		Expect(account.Attributes.OrganisationIdentification.Actors[0].Name).To(Equal([]string{"Jeff Page"}))
+		Expect(account.Relationships.MasterAccount.Data[0].ID).To(Equal("a52d13a4-f435-4c00-cfad-f5e7ac5972df"))
+
+		serialised, err := json.Marshal(account)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		expected := &bytes.Buffer{}
+		Expect(json.Compact(expected, data)).To(Succeed())
+
+		Expect(string(serialised)).To(Equal(expected.String()))
+	})
+
+	It("should keep false and zero values set by upstream", func() {
+		data := readData("testdata/account_unswitched.json")
+
+		account := &Account{}
+		Expect(json.Unmarshal(data, account)).To(Succeed())
+		Expect(*account.Attributes.Switched).To(BeFalse())
+
+		serialised, err := json.Marshal(account)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		expected := &bytes.Buffer{}
+		Expect(json.Compact(expected, data)).To(Succeed())
+
+		Expect(string(serialised)).To(Equal(expected.String()))
+	})
+
+	It("should keep every attribute of the fixtures", func() {
+		fixtures, err := filepath.Glob("../../fixtures/fetch_api.Account_*.json")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fixtures).NotTo(BeEmpty())
+
+		for _, fixture := range fixtures {
+			data := readData(fixture)
+
+			account := &Account{}
+			Expect(json.Unmarshal(data, account)).To(Succeed())
+			Expect(account.Validate()).To(Succeed(), fixture)
+
+			serialised, err := json.Marshal(account)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// Version is always sent, since upstream needs it for updates.
+			var expected map[string]interface{}
+			Expect(json.Unmarshal(data, &expected)).To(Succeed())
+			expected["version"] = 0
+
+			Expect(serialised).To(MatchJSON(mustMarshal(expected)), fixture)
+		}
+	})
+})
+
+var _ = Describe("Payment", func() {
+	It("should re-serialise a full payment byte for byte", func() {
+		data := readData("testdata/payment_full.json")
+
+		payment := &Payment{}
+		Expect(json.Unmarshal(data, payment)).To(Succeed())
+
+		Expect(payment.Attributes.Amount).To(Equal("100.21"))
+		Expect(*payment.Attributes.BeneficiaryParty.AccountType).To(Equal(1))
+		Expect(*payment.Attributes.DebtorParty.AccountType).To(Equal(0))
+		Expect(payment.Attributes.ChargesInformation.SenderCharges).To(HaveLen(2))
+		Expect(payment.Attributes.PaymentScheme).To(Equal(PaymentSchemeFPS))
+
+		serialised, err := json.Marshal(payment)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		expected := &bytes.Buffer{}
+		Expect(json.Compact(expected, data)).To(Succeed())
+
+		Expect(string(serialised)).To(Equal(expected.String()))
+	})
+
+	It("should be registered in the scheme", func() {
+		endpoint, err := Schema.GetEndpointForObj(&Payment{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(endpoint).To(Equal("transaction/payments/%s"))
+
+		obj, err := Schema.NewObj("api.PaymentList")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(obj).To(BeAssignableToTypeOf(&PaymentList{}))
+	})
+})
+
+func mustMarshal(value interface{}) []byte {
+	result, err := json.Marshal(value)
+	Expect(err).ShouldNot(HaveOccurred())
+
+	return result
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Validator is implemented by objects that can check themselves before being
+// sent upstream.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes a single invalid field, named by its JSON path.
+type FieldError struct {
+	Field   string
+	Value   interface{}
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// ValidationErrors lists every invalid field of an object.
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Error())
+	}
+
+	return fmt.Sprintf("validation failed: %s", strings.Join(messages, "; "))
+}
+
+// Fields returns the names of the invalid fields.
+func (e ValidationErrors) Fields() []string {
+	fields := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		fields = append(fields, fieldErr.Field)
+	}
+
+	return fields
+}
+
+func (e *ValidationErrors) add(field string, value interface{}, msgFmt string, args ...interface{}) {
+	*e = append(*e, &FieldError{Field: field, Value: value, Message: fmt.Sprintf(msgFmt, args...)})
+}
+
+// err returns nil when there are no failures, so callers don't end up with a
+// non nil error interface holding an empty list.
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// Validate runs the Validate hook of obj, if its type provides one.
+func (s *Scheme) Validate(obj Object) error {
+	if validator, ok := obj.(Validator); ok {
+		return validator.Validate()
+	}
+
+	return nil
+}
+
+var uuidRegexp = regexp.MustCompile(`^[[:xdigit:]]{8}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{12}$`)
+
+// IsUUID reports whether value is formatted as a uuid.
+func IsUUID(value string) bool {
+	return uuidRegexp.MatchString(value)
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func validAccount() *Account {
+	account := NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+	account.Type = "accounts"
+	account.OrganisationID = "721763e9-b2e2-4ebb-8de9-b440e3cf23a6"
+	account.Attributes = AccountAttributes{
+		Country:               "GB",
+		BaseCurrency:          "GBP",
+		BankID:                "400300",
+		BankIDCode:            "GBDSC",
+		BIC:                   "NWBKGB22",
+		AccountNumber:         "41426819",
+		IBAN:                  "GB16NWBK40030041426819",
+		AccountClassification: AccountClassificationPersonal,
+	}
+
+	return account
+}
+
+func fieldsOf(err error) []string {
+	var validationErrs ValidationErrors
+
+	Expect(errors.As(err, &validationErrs)).To(BeTrue())
+
+	return validationErrs.Fields()
+}
+
+var _ = Describe("Validate", func() {
+	It("should accept valid accounts, as values or pointers", func() {
+		Expect(Schema.Validate(validAccount())).To(Succeed())
+		Expect(Schema.Validate(*validAccount())).To(Succeed())
+	})
+
+	It("should ignore objects without a Validate hook", func() {
+		Expect(Schema.Validate(&AccountList{})).To(Succeed())
+	})
+
+	It("should report every invalid field", func() {
+		account := NewAccount("not-a-uuid", 0)
+		account.Attributes.BaseCurrency = "XXX"
+		account.Attributes.Status = "closed"
+
+		err := Schema.Validate(account)
+
+		Expect(fieldsOf(err)).To(Equal([]string{
+			"id",
+			"organisation_id",
+			"type",
+			"attributes.country",
+			"attributes.base_currency",
+			"attributes.account_classification",
+			"attributes.status",
+		}))
+		Expect(err).To(MatchError(ContainSubstring("attributes.country is required")))
+		Expect(err).To(MatchError(ContainSubstring(
+			"attributes.account_classification should be one of [Personal Business]")))
+	})
+
+	It("should reject unknown countries", func() {
+		account := validAccount()
+		account.Attributes.Country = "UK"
+
+		Expect(fieldsOf(Schema.Validate(account))).To(ContainElement("attributes.country"))
+	})
+
+	It("should apply the GB rules", func() {
+		account := validAccount()
+		account.Attributes.BankID = "40030"
+		account.Attributes.BankIDCode = "DEBLZ"
+		account.Attributes.AccountNumber = "4142681"
+		account.Attributes.BIC = ""
+
+		Expect(fieldsOf(Schema.Validate(account))).To(Equal([]string{
+			"attributes.bank_id",
+			"attributes.bank_id_code",
+			"attributes.bic",
+			"attributes.account_number",
+		}))
+	})
+
+	It("should apply the DE rules", func() {
+		account := validAccount()
+		account.Attributes.Country = "DE"
+		account.Attributes.BaseCurrency = "EUR"
+		account.Attributes.BankID = "37040044"
+		account.Attributes.BankIDCode = "DEBLZ"
+		account.Attributes.AccountNumber = "0532013000"
+		account.Attributes.IBAN = "DE89370400440532013000"
+
+		Expect(Schema.Validate(account)).To(Succeed())
+
+		account.Attributes.BankID = ""
+		Expect(fieldsOf(Schema.Validate(account))).To(Equal([]string{"attributes.bank_id"}))
+	})
+
+	It("should reject bank ids for NL", func() {
+		account := validAccount()
+		account.Attributes.Country = "NL"
+		account.Attributes.BaseCurrency = "EUR"
+		account.Attributes.AccountNumber = "0417164300"
+		account.Attributes.IBAN = ""
+
+		Expect(fieldsOf(Schema.Validate(account))).To(Equal([]string{
+			"attributes.bank_id",
+			"attributes.bank_id_code",
+		}))
+	})
+
+	It("should fall back to generic formats for other countries", func() {
+		account := validAccount()
+		account.Attributes.Country = "RO"
+		account.Attributes.BankID = "rncb"
+		account.Attributes.BankIDCode = ""
+		account.Attributes.IBAN = ""
+
+		Expect(fieldsOf(Schema.Validate(account))).To(Equal([]string{"attributes.bank_id"}))
+	})
+
+	It("should check the IBAN and BIC formats", func() {
+		account := validAccount()
+		account.Attributes.BIC = "NWBK"
+		account.Attributes.IBAN = "DE89370400440532013000"
+
+		Expect(fieldsOf(Schema.Validate(account))).To(Equal([]string{"attributes.bic", "attributes.iban"}))
+	})
+})
+
+var _ = Describe("Subscription", func() {
+	It("should validate the callback", func() {
+		subscription := NewSubscription("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+		subscription.Type = "subscriptions"
+		subscription.OrganisationID = "721763e9-b2e2-4ebb-8de9-b440e3cf23a6"
+		subscription.Attributes = SubscriptionAttributes{
+			CallbackTransport: CallbackTransportHTTP,
+			CallbackURI:       "https://example.com/form3/callbacks",
+			RecordType:        "accounts",
+			EventType:         EventTypeCreated,
+		}
+
+		Expect(Schema.Validate(subscription)).To(Succeed())
+
+		subscription.Attributes.CallbackURI = "/form3/callbacks"
+		subscription.Attributes.EventType = ""
+
+		Expect(fieldsOf(Schema.Validate(subscription))).To(Equal([]string{
+			"attributes.callback_uri",
+			"attributes.event_type",
+		}))
+	})
+})
@@ -0,0 +1,430 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var ErrApplyFailed = errors.New("apply failed")
+
+type ApplyAction string
+
+const (
+	ApplyCreate    ApplyAction = "create"
+	ApplyUpdate    ApplyAction = "update"
+	ApplyDelete    ApplyAction = "delete"
+	ApplyUnchanged ApplyAction = "unchanged"
+)
+
+// FieldDiff is a field whose value differs between the current and the
+// desired state. Old is nil for fields being added, New for fields of
+// resources being deleted.
+type FieldDiff struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+func (d FieldDiff) String() string { // nolint: gocritic
+	return fmt.Sprintf("%s: %s -> %s", d.Field, printValue(d.Old), printValue(d.New))
+}
+
+func printValue(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(body)
+}
+
+// ApplyResult is what Apply did, or would do in dry-run mode, to converge
+// a resource. Object is the resource as sent upstream, or as found there
+// when unchanged or deleted. Action is empty if Err kept Apply from telling
+// what to do, e.g. the resource couldn't be fetched or diffed.
+type ApplyResult struct {
+	Action ApplyAction
+	Object api.Object
+	Diff   []FieldDiff
+	Err    error
+}
+
+// ApplyOptions tunes Apply. Only the fields set in the desired objects are
+// converged: empty strings, and booleans or numbers which aren't pointers,
+// can't be told apart from fields left out, so they're never set upstream.
+type ApplyOptions struct {
+	// DryRun computes the results without changing anything upstream.
+	DryRun bool
+	// Prune deletes the resources of the applied kinds, under the same
+	// parents, which aren't desired.
+	Prune bool
+	// PruneOptions narrows down the resources considered for pruning.
+	PruneOptions *ListOptions
+}
+
+// managed reports whether Apply converges field. The version is always
+// taken from the current state.
+func managed(field string) bool {
+	return field != "version"
+}
+
+// unset reports whether a JSON value was most likely left out of a
+// manifest. Optional fields are omitted when empty, so false and 0 are only
+// encoded by pointer fields, which were set on purpose.
+func unset(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	}
+
+	return false
+}
+
+func toMap(obj interface{}) (map[string]interface{}, error) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var value map[string]interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// fields flattens the JSON representation of obj into dotted paths, leaving
+// unset values out. Arrays are compared as a whole.
+func fields(obj interface{}) (map[string]interface{}, error) {
+	value, err := toMap(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{}
+	flattenFields("", value, result)
+
+	return result, nil
+}
+
+func flattenFields(prefix string, value, result map[string]interface{}) {
+	for key, child := range value {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		if nested, ok := child.(map[string]interface{}); ok {
+			flattenFields(path, nested, result)
+			continue
+		}
+
+		if !unset(child) {
+			result[path] = child
+		}
+	}
+}
+
+// mergeFields sets the values of src which aren't unset into dst.
+func mergeFields(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if nested, ok := value.(map[string]interface{}); ok {
+			if current, ok := dst[key].(map[string]interface{}); ok {
+				mergeFields(current, nested)
+				continue
+			}
+		}
+
+		if !unset(value) {
+			dst[key] = value
+		}
+	}
+}
+
+func sortDiff(diff []FieldDiff) []FieldDiff {
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Field < diff[j].Field })
+
+	return diff
+}
+
+// Diff returns the managed fields set in desired whose value differs in
+// current, sorted by path. Fields left unset in desired are ignored.
+func Diff(current, desired api.Object) ([]FieldDiff, error) {
+	currentFields, err := fields(current)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredFields, err := fields(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var diff []FieldDiff
+
+	for field, value := range desiredFields {
+		if managed(field) && !reflect.DeepEqual(currentFields[field], value) {
+			diff = append(diff, FieldDiff{Field: field, Old: currentFields[field], New: value})
+		}
+	}
+
+	return sortDiff(diff), nil
+}
+
+// every lists every field of obj, as being added or removed.
+func every(obj api.Object, removed bool) ([]FieldDiff, error) {
+	objFields, err := fields(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make([]FieldDiff, 0, len(objFields))
+
+	for field, value := range objFields {
+		if !managed(field) {
+			continue
+		}
+
+		if removed {
+			diff = append(diff, FieldDiff{Field: field, Old: value})
+		} else {
+			diff = append(diff, FieldDiff{Field: field, New: value})
+		}
+	}
+
+	return sortDiff(diff), nil
+}
+
+// Apply converges upstream towards the desired objects: missing ones are
+// created, and ones whose managed fields differ are updated at their
+// current version. With Prune, resources not desired are deleted too.
+//
+// Every object is applied even if some fail. Their errors are reported in
+// the results, and Apply then returns ErrApplyFailed.
+func Apply(ctx context.Context, client Client, desired []api.Object, opts *ApplyOptions) ([]*ApplyResult, error) {
+	if opts == nil {
+		opts = &ApplyOptions{}
+	}
+
+	results := make([]*ApplyResult, 0, len(desired))
+
+	for _, obj := range desired {
+		results = append(results, applyOne(ctx, client, obj, opts))
+	}
+
+	if opts.Prune {
+		pruned, err := prune(ctx, client, desired, opts)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, pruned...)
+	}
+
+	failed := 0
+
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%w: %d of %d resources", ErrApplyFailed, failed, len(results))
+	}
+
+	return results, nil
+}
+
+// merged returns current with the fields set in desired, at the current
+// version.
+func merged(current, desired api.Object) (api.Object, error) {
+	value, err := toMap(current)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredValue, err := toMap(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(desiredValue, "version")
+	mergeFields(value, desiredValue)
+
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := reflect.New(reflect.TypeOf(desired).Elem()).Interface().(api.Object)
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// emptyLike returns an empty object of the type of obj, with its ID and
+// parent, to fetch obj into without touching it.
+func emptyLike(obj api.Object) (api.Object, error) {
+	v, err := api.EnforcePtr(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	empty := reflect.New(v.Type()).Interface().(api.Object)
+
+	if setter, ok := empty.(interface{ SetID(string) }); ok {
+		setter.SetID(obj.GetID())
+	}
+
+	if child, ok := obj.(api.Child); ok {
+		if setter, ok := empty.(api.ParentSetter); ok {
+			setter.SetParentID(child.GetParentID())
+		}
+	}
+
+	return empty, nil
+}
+
+func applyOne(ctx context.Context, client Client, obj api.Object, opts *ApplyOptions) *ApplyResult {
+	result := &ApplyResult{Object: obj}
+
+	current, err := emptyLike(obj)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	err = client.Fetch(ctx, current)
+
+	switch {
+	case IsNotFound(err):
+		if result.Diff, result.Err = every(obj, false); result.Err != nil {
+			return result
+		}
+
+		result.Action = ApplyCreate
+
+		if !opts.DryRun {
+			result.Err = client.Create(ctx, obj)
+		}
+
+		return result
+	case err != nil:
+		result.Err = err
+		return result
+	}
+
+	result.Object = current
+
+	if result.Diff, result.Err = Diff(result.Object, obj); result.Err != nil {
+		return result
+	}
+
+	if len(result.Diff) == 0 {
+		result.Action = ApplyUnchanged
+		return result
+	}
+
+	updated, err := merged(result.Object, obj)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Action = ApplyUpdate
+	result.Object = updated
+
+	if !opts.DryRun {
+		result.Err = client.Update(ctx, updated)
+	}
+
+	return result
+}
+
+// listKey identifies a list of resources of the same kind, under the same
+// parent.
+type listKey struct {
+	kind   string
+	parent string
+}
+
+func prune(ctx context.Context, client Client, desired []api.Object, opts *ApplyOptions) ([]*ApplyResult, error) {
+	var keys []listKey
+
+	wanted := map[listKey]map[string]bool{}
+
+	for _, obj := range desired {
+		key := listKey{kind: api.Schema.TypeName(obj)}
+		if child, ok := obj.(api.Child); ok {
+			key.parent = child.GetParentID()
+		}
+
+		if wanted[key] == nil {
+			wanted[key] = map[string]bool{}
+			keys = append(keys, key)
+		}
+
+		wanted[key][obj.GetID()] = true
+	}
+
+	var results []*ApplyResult
+
+	for _, key := range keys {
+		list, err := api.Schema.NewObj(key.kind + "List")
+		if err != nil {
+			return results, err
+		}
+
+		if setter, ok := list.(api.ParentSetter); ok {
+			setter.SetParentID(key.parent)
+		}
+
+		if err := client.List(ctx, list, opts.PruneOptions); err != nil {
+			return results, err
+		}
+
+		items := reflect.ValueOf(list).Elem().FieldByName("Items")
+
+		for i := 0; i < items.Len(); i++ {
+			obj := items.Index(i).Addr().Interface().(api.Object)
+			if wanted[key][obj.GetID()] {
+				continue
+			}
+
+			result := &ApplyResult{Object: obj}
+
+			if result.Diff, result.Err = every(obj, true); result.Err == nil {
+				result.Action = ApplyDelete
+
+				if !opts.DryRun {
+					result.Err = client.Delete(ctx, obj)
+				}
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// Apply converges upstream towards the desired objects, see Apply.
+func (c *Form3Client) Apply(ctx context.Context, desired []api.Object, opts *ApplyOptions) ([]*ApplyResult, error) {
+	return Apply(ctx, c, desired, opts)
+}
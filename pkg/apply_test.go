@@ -0,0 +1,217 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+// unencodable fails to be diffed, since funcs can't be encoded to JSON.
+type unencodable struct {
+	api.Resource
+	Callback func() `json:"callback"`
+}
+
+func (u unencodable) GetID() string { return u.ID }
+
+func (u unencodable) GetVersion() int { return u.Version }
+
+// fetchClient answers every Fetch with err.
+type fetchClient struct {
+	Client
+	err error
+}
+
+func (c *fetchClient) Fetch(ctx context.Context, obj api.Object) error {
+	return c.err
+}
+
+var _ = Describe("Apply", func() {
+	const (
+		existingID = "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"
+		missingID  = "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
+		extraID    = "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5"
+	)
+
+	var (
+		server      *fakeapi.Server
+		form3Client Client
+	)
+
+	fetch := func(id string) (*api.Account, error) {
+		account := api.NewAccount(id, 0)
+		return account, form3Client.Fetch(context.TODO(), account)
+	}
+
+	BeforeEach(func() {
+		server = fakeapi.NewServer()
+		Expect(server.Seed(newGBAccount(existingID, 0), newGBAccount(extraID, 0))).To(Succeed())
+
+		form3Client = NewClient(WithBaseURL(server.URL))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should diff the fields set in the desired state", func() {
+		current := newGBAccount(existingID, 3)
+		current.Attributes.Name = []string{"Samantha Jones"}
+
+		desired := api.NewAccount(existingID, 0)
+		desired.Attributes.BankID = "400302"
+		desired.Attributes.Name = []string{"Sam Jones"}
+		desired.Attributes.CustomerID = "1234"
+
+		diff, err := Diff(current, desired)
+		Expect(err).NotTo(HaveOccurred())
+
+		var lines []string
+		for _, field := range diff {
+			lines = append(lines, field.String())
+		}
+
+		Expect(lines).To(Equal([]string{
+			`attributes.bank_id: "400300" -> "400302"`,
+			`attributes.customer_id: <none> -> "1234"`,
+			`attributes.name: ["Samantha Jones"] -> ["Sam Jones"]`,
+		}))
+	})
+
+	It("should create, update and leave resources unchanged", func() {
+		changed := newGBAccount(existingID, 0)
+		changed.Attributes.BankID = "400302"
+
+		results, err := form3Client.Apply(context.TODO(), []api.Object{
+			changed,
+			newGBAccount(missingID, 0),
+			newGBAccount(extraID, 0),
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(3))
+
+		Expect(results[0].Action).To(Equal(ApplyUpdate))
+		Expect(results[0].Diff).To(Equal([]FieldDiff{{Field: "attributes.bank_id", Old: "400300", New: "400302"}}))
+		Expect(results[1].Action).To(Equal(ApplyCreate))
+		Expect(results[2].Action).To(Equal(ApplyUnchanged))
+
+		account, err := fetch(existingID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(account.Attributes.BankID).To(Equal("400302"))
+		Expect(account.Version).To(Equal(1))
+
+		_, err = fetch(missingID)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should update resources whatever their version", func() {
+		account, err := fetch(existingID)
+		Expect(err).NotTo(HaveOccurred())
+
+		account.Attributes.CustomerID = "1234"
+		Expect(form3Client.Update(context.TODO(), account)).To(Succeed())
+
+		desired := newGBAccount(existingID, 0)
+		desired.Attributes.CustomerID = "5678"
+
+		_, err = form3Client.Apply(context.TODO(), []api.Object{desired}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		account, err = fetch(existingID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(account.Attributes.CustomerID).To(Equal("5678"))
+		Expect(account.Version).To(Equal(2))
+	})
+
+	It("should only report changes in dry-run mode", func() {
+		changed := newGBAccount(existingID, 0)
+		changed.Attributes.BankID = "400302"
+
+		results, err := form3Client.Apply(context.TODO(), []api.Object{changed, newGBAccount(missingID, 0)},
+			&ApplyOptions{DryRun: true, Prune: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		var actions []ApplyAction
+		for _, result := range results {
+			actions = append(actions, result.Action)
+		}
+
+		Expect(actions).To(Equal([]ApplyAction{ApplyUpdate, ApplyCreate, ApplyDelete}))
+		Expect(results[2].Object.GetID()).To(Equal(extraID))
+
+		account, err := fetch(existingID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(account.Attributes.BankID).To(Equal("400300"))
+
+		_, err = fetch(missingID)
+		Expect(IsNotFound(err)).To(BeTrue())
+
+		_, err = fetch(extraID)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should prune resources which aren't desired", func() {
+		results, err := form3Client.Apply(context.TODO(), []api.Object{newGBAccount(existingID, 0)},
+			&ApplyOptions{Prune: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+		Expect(results[1].Action).To(Equal(ApplyDelete))
+
+		_, err = fetch(extraID)
+		Expect(IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should set pointer fields back to false", func() {
+		switched, unswitched := true, false
+
+		desired := newGBAccount(existingID, 0)
+		desired.Attributes.Switched = &switched
+
+		_, err := form3Client.Apply(context.TODO(), []api.Object{desired}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		desired.Attributes.Switched = &unswitched
+
+		results, err := form3Client.Apply(context.TODO(), []api.Object{desired}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results[0].Action).To(Equal(ApplyUpdate))
+		Expect(results[0].Diff).To(Equal([]FieldDiff{{Field: "attributes.switched", Old: true, New: false}}))
+
+		account, err := fetch(existingID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(account.Attributes.Switched).To(Equal(&unswitched))
+	})
+
+	It("should leave the action empty when resources can't be diffed", func() {
+		for _, fetchErr := range []error{nil, &APIError{StatusCode: http.StatusNotFound}} {
+			desired := &unencodable{Resource: api.Resource{ID: missingID}, Callback: func() {}}
+
+			results, err := Apply(context.TODO(), &fetchClient{err: fetchErr}, []api.Object{desired}, nil)
+			Expect(errors.Is(err, ErrApplyFailed)).To(BeTrue())
+			Expect(results[0].Action).To(BeEmpty())
+			Expect(results[0].Err).To(HaveOccurred())
+		}
+	})
+
+	It("should apply every resource and report failures", func() {
+		invalid := newGBAccount(missingID, 0)
+		invalid.Attributes.Country = "XX"
+
+		changed := newGBAccount(existingID, 0)
+		changed.Attributes.BankID = "400302"
+
+		results, err := form3Client.Apply(context.TODO(), []api.Object{invalid, changed}, nil)
+		Expect(errors.Is(err, ErrApplyFailed)).To(BeTrue())
+		Expect(err).To(MatchError("apply failed: 1 of 2 resources"))
+
+		var validationErrs api.ValidationErrors
+		Expect(errors.As(results[0].Err, &validationErrs)).To(BeTrue())
+		Expect(results[1].Err).NotTo(HaveOccurred())
+	})
+})
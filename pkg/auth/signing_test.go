@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Signer", func() {
+	var (
+		key      *rsa.PrivateKey
+		verifier *Verifier
+		server   *httptest.Server
+		client   *http.Client
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		verifier = NewVerifier("75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", &key.PublicKey)
+		server = httptest.NewServer(verifier.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})))
+
+		signer := NewSigner("75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", key)
+		client = &http.Client{Transport: signer.Transport(http.DefaultTransport)}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should sign requests without a body", func() {
+		resp, err := client.Get(server.URL + "/v1/organisation/accounts?page[size]=1")
+		Expect(err).ShouldNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+	})
+
+	It("should sign requests with a body and its digest", func() {
+		resp, err := client.Post(server.URL+"/v1/organisation/accounts", "application/json",
+			bytes.NewBufferString(`{"data": {}}`))
+		Expect(err).ShouldNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+	})
+
+	It("should reject unsigned requests", func() {
+		resp, err := http.Get(server.URL + "/v1/organisation/accounts")
+		Expect(err).ShouldNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject tampered bodies", func() {
+		req, err := http.NewRequest(http.MethodPost, "http://form3.test/v1/organisation/accounts",
+			bytes.NewBufferString(`{"data": {}}`))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(NewSigner("75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", key).Sign(req)).To(Succeed())
+
+		req.GetBody = nil
+		req.Body = ioutil.NopCloser(bytes.NewBufferString(`{"data": {"id": "x"}}`))
+
+		Expect(verifier.Verify(req)).To(MatchError(ErrDigestMismatch))
+	})
+
+	It("should reject signatures from unknown keys", func() {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		req, err := http.NewRequest(http.MethodGet, "http://form3.test/v1/organisation/accounts", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(NewSigner("75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", other).Sign(req)).To(Succeed())
+		Expect(verifier.Verify(req)).To(MatchError(ErrInvalidSignature))
+
+		Expect(NewSigner("unknown", key).Sign(req)).To(Succeed())
+		Expect(verifier.Verify(req)).To(MatchError(ErrUnknownKey))
+	})
+
+	It("should reject stale signatures", func() {
+		signer := NewSigner("75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", key)
+		signer.now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+		req, err := http.NewRequest(http.MethodGet, "http://form3.test/v1/organisation/accounts", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(signer.Sign(req)).To(Succeed())
+		Expect(verifier.Verify(req)).To(MatchError(ErrExpiredSignature))
+	})
+
+	It("should work when built as literals", func() {
+		req, err := http.NewRequest(http.MethodGet, "http://form3.test/v1/organisation/accounts", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect((&Signer{KeyID: "75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", Key: key}).Sign(req)).To(Succeed())
+
+		literal := &Verifier{
+			Keys:    map[string]*rsa.PublicKey{"75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8": &key.PublicKey},
+			MaxSkew: time.Minute,
+		}
+		Expect(literal.Verify(req)).To(Succeed())
+	})
+
+	It("should reject signatures which don't cover the request target, date and digest", func() {
+		signWith := func(req *http.Request, headers ...string) {
+			toSign, err := signingString(req, headers)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			hashed := sha256.Sum256([]byte(toSign))
+			signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+			Expect(err).ShouldNot(HaveOccurred())
+
+			req.Header.Set("Authorization", fmt.Sprintf(`Signature keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+				"75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", SignatureAlgorithm, strings.Join(headers, " "),
+				base64.StdEncoding.EncodeToString(signature)))
+		}
+
+		req, err := http.NewRequest(http.MethodGet, "http://form3.test/v1/organisation/accounts", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+
+		signWith(req, "host")
+		Expect(errors.Is(verifier.Verify(req), ErrUnsignedHeader)).To(BeTrue())
+
+		signWith(req, RequestTarget, "host")
+		Expect(verifier.Verify(req)).To(MatchError("required header isn't signed: date"))
+
+		signWith(req, RequestTarget, "date")
+		Expect(verifier.Verify(req)).To(Succeed())
+
+		body := `{"data": {}}`
+		req, err = http.NewRequest(http.MethodPost, "http://form3.test/v1/organisation/accounts", bytes.NewBufferString(body))
+		Expect(err).ShouldNot(HaveOccurred())
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+		req.Header.Set("Digest", Digest([]byte(body)))
+
+		signWith(req, RequestTarget, "date")
+		Expect(verifier.Verify(req)).To(MatchError("required header isn't signed: digest"))
+	})
+})
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRefreshBefore = time.Minute
+
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+
+	Expiry time.Time `json:"-"`
+}
+
+// TokenSource hands out access tokens. Implementations must be safe for
+// concurrent use.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+	// Invalidate drops the given token, if it's still the cached one, so the
+	// next call to Token fetches a new one.
+	Invalidate(token *Token)
+}
+
+// ClientCredentials fetches tokens from an OAuth2 token endpoint using the
+// client credentials grant and caches them until shortly before they expire.
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// RefreshBefore is how long before expiry a cached token is replaced.
+	RefreshBefore time.Duration
+	HTTPClient    *http.Client
+
+	mu    sync.Mutex
+	token *Token
+	now   func() time.Time
+
+	// refreshing is closed once the fetch in flight, if any, is done.
+	refreshing chan struct{}
+}
+
+func NewClientCredentials(tokenURL, clientID, clientSecret string, scopes ...string) *ClientCredentials {
+	return &ClientCredentials{
+		TokenURL:      tokenURL,
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		Scopes:        scopes,
+		RefreshBefore: defaultRefreshBefore,
+		HTTPClient:    &http.Client{Timeout: 30 * time.Second},
+		now:           time.Now,
+	}
+}
+
+// currentTime falls back to time.Now for ClientCredentials built as literals.
+func (c *ClientCredentials) currentTime() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+
+	return c.now()
+}
+
+func (c *ClientCredentials) valid(token *Token) bool {
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+
+	if token.Expiry.IsZero() {
+		return true
+	}
+
+	return c.currentTime().Add(c.RefreshBefore).Before(token.Expiry)
+}
+
+// Token returns the cached token, or fetches a new one. A single fetch runs at
+// a time, without holding the lock, and callers waiting for it give up as
+// soon as their context is done.
+func (c *ClientCredentials) Token(ctx context.Context) (*Token, error) {
+	for {
+		c.mu.Lock()
+
+		if c.valid(c.token) {
+			token := c.token
+			c.mu.Unlock()
+
+			return token, nil
+		}
+
+		refreshing := c.refreshing
+		if refreshing == nil {
+			done := make(chan struct{})
+			c.refreshing = done
+			c.mu.Unlock()
+
+			return c.refresh(ctx, done)
+		}
+
+		c.mu.Unlock()
+
+		// The fetch in flight may fail, e.g. if its caller gave up, in which
+		// case the next waiter fetches a token itself.
+		select {
+		case <-refreshing:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *ClientCredentials) refresh(ctx context.Context, done chan struct{}) (*Token, error) {
+	token, err := c.fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		c.token = token
+	}
+
+	c.refreshing = nil
+	close(done)
+
+	return token, err
+}
+
+func (c *ClientCredentials) Invalidate(token *Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == token {
+		c.token = nil
+	}
+}
+
+func (c *ClientCredentials) fetch(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("couldn't fetch token: %s: %s", resp.Status, body)
+	}
+
+	token := &Token{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, err
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("couldn't fetch token: missing access_token")
+	}
+
+	if token.ExpiresIn > 0 {
+		token.Expiry = c.currentTime().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+// NewTokenTransport authorizes every request with a bearer token from ts. A
+// request rejected with 401 is retried once with a fresh token.
+func NewTokenTransport(ts TokenSource, next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, token, err := roundTripWithToken(ts, next, req)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+
+		replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if !replayable {
+			return resp, nil
+		}
+
+		ts.Invalidate(token)
+
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+
+			retry.Body = body
+		}
+
+		resp.Body.Close()
+
+		resp, _, err = roundTripWithToken(ts, next, retry)
+
+		return resp, err
+	})
+}
+
+func roundTripWithToken(ts TokenSource, next http.RoundTripper, req *http.Request) (*http.Response, *Token, error) {
+	token, err := ts.Token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		return nil, nil, err
+	}
+
+	authorized := req.Clone(req.Context())
+
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	authorized.Header.Set("Authorization", fmt.Sprintf("%s %s", tokenType, token.AccessToken))
+
+	resp, err := next.RoundTrip(authorized)
+
+	return resp, token, err
+}
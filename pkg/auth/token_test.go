@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientCredentials", func() {
+	var (
+		tokenServer *httptest.Server
+		apiServer   *httptest.Server
+		issued      int32
+		revoked     sync.Map
+		expiresIn   int
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&issued, 0)
+		revoked = sync.Map{}
+		expiresIn = 3600
+
+		tokenServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientID, secret, ok := r.BasicAuth()
+			if !ok || clientID != "client" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			token := atomic.AddInt32(&issued, 1)
+			_, _ = fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "bearer", "expires_in": %d}`, token, expiresIn)
+		}))
+
+		apiServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, isRevoked := revoked.Load(r.Header.Get("Authorization")); isRevoked {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+		}))
+	})
+
+	AfterEach(func() {
+		tokenServer.Close()
+		apiServer.Close()
+	})
+
+	It("should cache tokens across concurrent requests", func() {
+		source := NewClientCredentials(tokenServer.URL, "client", "secret")
+		client := &http.Client{Transport: NewTokenTransport(source, http.DefaultTransport)}
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				resp, err := client.Get(apiServer.URL)
+				Expect(err).ShouldNot(HaveOccurred())
+				resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			}()
+		}
+
+		wg.Wait()
+
+		Expect(atomic.LoadInt32(&issued)).To(BeEquivalentTo(1))
+	})
+
+	It("should refresh tokens before they expire", func() {
+		expiresIn = 30
+
+		source := NewClientCredentials(tokenServer.URL, "client", "secret")
+
+		first, err := source.Token(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+
+		second, err := source.Token(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(first.AccessToken).To(Equal("token-1"))
+		Expect(second.AccessToken).To(Equal("token-2"))
+
+		source.RefreshBefore = 10 * time.Second
+
+		third, err := source.Token(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(third).To(BeIdenticalTo(second))
+	})
+
+	It("should retry once with a fresh token on 401", func() {
+		revoked.Store("Bearer token-1", true)
+
+		source := NewClientCredentials(tokenServer.URL, "client", "secret")
+		client := &http.Client{Transport: NewTokenTransport(source, http.DefaultTransport)}
+
+		resp, err := client.Get(apiServer.URL)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(atomic.LoadInt32(&issued)).To(BeEquivalentTo(2))
+	})
+
+	It("should give up after a second 401", func() {
+		revoked.Store("Bearer token-1", true)
+		revoked.Store("Bearer token-2", true)
+
+		source := NewClientCredentials(tokenServer.URL, "client", "secret")
+		client := &http.Client{Transport: NewTokenTransport(source, http.DefaultTransport)}
+
+		resp, err := client.Get(apiServer.URL)
+		Expect(err).ShouldNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(atomic.LoadInt32(&issued)).To(BeEquivalentTo(2))
+	})
+
+	It("should fail for invalid credentials", func() {
+		source := NewClientCredentials(tokenServer.URL, "client", "wrong")
+
+		_, err := source.Token(context.TODO())
+		Expect(err).Should(HaveOccurred())
+	})
+
+	It("should work when built as a literal", func() {
+		source := &ClientCredentials{TokenURL: tokenServer.URL, ClientID: "client", ClientSecret: "secret"}
+
+		first, err := source.Token(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+
+		second, err := source.Token(context.TODO())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("should not block callers whose context is done on a slow fetch", func() {
+		release := make(chan struct{})
+		slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			_, _ = w.Write([]byte(`{"access_token": "slow", "expires_in": 3600}`))
+		}))
+
+		defer slowServer.Close()
+		defer close(release)
+
+		source := NewClientCredentials(slowServer.URL, "client", "secret")
+
+		fetched := make(chan *Token)
+
+		go func() {
+			token, _ := source.Token(context.TODO())
+			fetched <- token
+		}()
+
+		Eventually(func() bool {
+			source.mu.Lock()
+			defer source.mu.Unlock()
+
+			return source.refreshing != nil
+		}).Should(BeTrue())
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := source.Token(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+
+		release <- struct{}{}
+		Expect((<-fetched).AccessToken).To(Equal("slow"))
+	})
+})
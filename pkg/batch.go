@@ -0,0 +1,159 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var ErrBatchFailed = errors.New("batch failed")
+
+const DefaultBatchParallelism = 4
+
+// Verbs of batches.
+const (
+	BatchVerbCreate = "create"
+	BatchVerbDelete = "delete"
+	BatchVerbFetch  = "fetch"
+)
+
+type BatchOptions struct {
+	// Parallelism is the number of requests sent at once,
+	// DefaultBatchParallelism if not set. Requests still wait on the client
+	// RateLimiter, if any.
+	Parallelism int
+}
+
+// BatchResult is the outcome of a batch for one of its objects.
+type BatchResult struct {
+	Object api.Object
+	Err    error
+}
+
+// BatchError lists the objects of a batch which failed, in the order they
+// were given. errors.Is(err, ErrBatchFailed) matches it.
+type BatchError struct {
+	Verb     string
+	Total    int
+	Failures []*BatchResult
+}
+
+func (e *BatchError) Error() string {
+	messages := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		messages = append(messages, fmt.Sprintf("%s: %s", failure.Object.GetID(), failure.Err))
+	}
+
+	return fmt.Sprintf("batch %s failed: %d of %d objects: %s", e.Verb, len(e.Failures), e.Total, strings.Join(messages, "; "))
+}
+
+func (e *BatchError) Is(target error) bool {
+	return target == ErrBatchFailed
+}
+
+// IDs returns the IDs of the objects which failed.
+func (e *BatchError) IDs() []string {
+	ids := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		ids = append(ids, failure.Object.GetID())
+	}
+
+	return ids
+}
+
+// batch calls do for every object, from at most Parallelism goroutines. Once
+// ctx is done, the objects left are failed with its error instead.
+func batch(ctx context.Context, verb string, objs []api.Object, opts *BatchOptions, do func(context.Context, api.Object) error) ([]*BatchResult, error) {
+	parallelism := DefaultBatchParallelism
+	if opts != nil && opts.Parallelism > 0 {
+		parallelism = opts.Parallelism
+	}
+
+	results := make([]*BatchResult, len(objs))
+	for i, obj := range objs {
+		results[i] = &BatchResult{Object: obj}
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < parallelism && w < len(objs); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				results[i].Err = do(ctx, results[i].Object)
+			}
+		}()
+	}
+
+	for i := range results {
+		// ctx is checked first, so no object is sent once it's done even if
+		// a worker is free.
+		if ctx.Err() == nil {
+			select {
+			case indexes <- i:
+				continue
+			case <-ctx.Done():
+			}
+		}
+
+		results[i].Err = ctx.Err()
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	batchErr := &BatchError{Verb: verb, Total: len(results)}
+
+	for _, result := range results {
+		if result.Err != nil {
+			batchErr.Failures = append(batchErr.Failures, result)
+		}
+	}
+
+	if len(batchErr.Failures) > 0 {
+		return results, batchErr
+	}
+
+	return results, nil
+}
+
+// BatchCreate creates objs concurrently. Every object is attempted even if
+// some fail: the results, in the order of objs, hold their errors, and
+// BatchCreate then returns a *BatchError.
+func BatchCreate(ctx context.Context, client Client, objs []api.Object, opts *BatchOptions) ([]*BatchResult, error) {
+	return batch(ctx, BatchVerbCreate, objs, opts, client.Create)
+}
+
+// BatchDelete deletes objs concurrently, at their version, see BatchCreate.
+func BatchDelete(ctx context.Context, client Client, objs []api.Object, opts *BatchOptions) ([]*BatchResult, error) {
+	return batch(ctx, BatchVerbDelete, objs, opts, client.Delete)
+}
+
+// BatchFetch fetches objs concurrently, each into itself, see BatchCreate.
+func BatchFetch(ctx context.Context, client Client, objs []api.Object, opts *BatchOptions) ([]*BatchResult, error) {
+	return batch(ctx, BatchVerbFetch, objs, opts, client.Fetch)
+}
+
+// BatchCreate creates objs concurrently, see BatchCreate.
+func (c *Form3Client) BatchCreate(ctx context.Context, objs []api.Object, opts *BatchOptions) ([]*BatchResult, error) {
+	return BatchCreate(ctx, c, objs, opts)
+}
+
+// BatchDelete deletes objs concurrently, see BatchDelete.
+func (c *Form3Client) BatchDelete(ctx context.Context, objs []api.Object, opts *BatchOptions) ([]*BatchResult, error) {
+	return BatchDelete(ctx, c, objs, opts)
+}
+
+// BatchFetch fetches objs concurrently, see BatchFetch.
+func (c *Form3Client) BatchFetch(ctx context.Context, objs []api.Object, opts *BatchOptions) ([]*BatchResult, error) {
+	return BatchFetch(ctx, c, objs, opts)
+}
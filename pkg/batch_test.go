@@ -0,0 +1,146 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+var _ = Describe("Batch", func() {
+	ids := []string{
+		"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		"4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43",
+		"9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5",
+		"93bfaa94-9e48-402d-9744-6ef85c6303b0",
+		"0f1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d",
+	}
+
+	var server *fakeapi.Server
+
+	accounts := func(version int) []api.Object {
+		objs := make([]api.Object, 0, len(ids))
+		for _, id := range ids {
+			objs = append(objs, newGBAccount(id, version))
+		}
+
+		return objs
+	}
+
+	BeforeEach(func() {
+		server = fakeapi.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create, fetch and delete every object", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		results, err := form3Client.BatchCreate(context.TODO(), accounts(0), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(len(ids)))
+
+		fetched := make([]api.Object, 0, len(ids))
+		for _, id := range ids {
+			fetched = append(fetched, api.NewAccount(id, 0))
+		}
+
+		results, err = form3Client.BatchFetch(context.TODO(), fetched, &BatchOptions{Parallelism: 2})
+		Expect(err).NotTo(HaveOccurred())
+
+		for i, result := range results {
+			Expect(result.Object.GetID()).To(Equal(ids[i]))
+			Expect(result.Object.(*api.Account).Attributes.BankID).To(Equal("400300"))
+		}
+
+		_, err = form3Client.BatchDelete(context.TODO(), fetched, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		list := &api.AccountList{}
+		Expect(form3Client.List(context.TODO(), list, nil)).To(Succeed())
+		Expect(list.Items).To(BeEmpty())
+	})
+
+	It("should carry on and report which objects failed", func() {
+		Expect(server.Seed(newGBAccount(ids[3], 0))).To(Succeed())
+
+		objs := accounts(0)
+		objs[1].(*api.Account).Attributes.Country = "XX"
+
+		results, err := NewClient(WithBaseURL(server.URL)).BatchCreate(context.TODO(), objs, nil)
+		Expect(errors.Is(err, ErrBatchFailed)).To(BeTrue())
+
+		var batchErr *BatchError
+		Expect(errors.As(err, &batchErr)).To(BeTrue())
+		Expect(batchErr.Verb).To(Equal(BatchVerbCreate))
+		Expect(batchErr.Total).To(Equal(5))
+		Expect(batchErr.IDs()).To(Equal([]string{ids[1], ids[3]}))
+		Expect(err.Error()).To(HavePrefix("batch create failed: 2 of 5 objects: " + ids[1] + ": validation failed: "))
+
+		Expect(results[0].Err).NotTo(HaveOccurred())
+		Expect(errors.Is(results[3].Err, ErrConflict)).To(BeTrue())
+	})
+
+	It("should send at most Parallelism requests at once, through the rate limiter", func() {
+		var (
+			mu      sync.Mutex
+			running int
+			peak    int
+		)
+
+		limiter := NewRateLimiter(1000, 1000)
+		form3Client := NewClient(WithBaseURL(server.URL), WithRateLimiter(limiter), WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				running++
+				if running > peak {
+					peak = running
+				}
+				mu.Unlock()
+
+				defer func() {
+					mu.Lock()
+					running--
+					mu.Unlock()
+				}()
+
+				time.Sleep(20 * time.Millisecond)
+
+				return next.RoundTrip(req)
+			})
+		}))
+
+		_, err := form3Client.BatchCreate(context.TODO(), accounts(0), &BatchOptions{Parallelism: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(peak).To(Equal(2))
+		Expect(limiter.Stats().Requests).To(Equal(int64(len(ids))))
+	})
+
+	It("should fail the objects left once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				cancel()
+				return next.RoundTrip(req)
+			})
+		}))
+
+		results, err := form3Client.BatchCreate(ctx, accounts(0), &BatchOptions{Parallelism: 1})
+		Expect(errors.Is(err, ErrBatchFailed)).To(BeTrue())
+		Expect(err.(*BatchError).Failures).To(HaveLen(len(ids)))
+
+		for _, result := range results {
+			Expect(errors.Is(result.Err, context.Canceled)).To(BeTrue())
+		}
+	})
+})
@@ -0,0 +1,238 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without calling upstream, while a
+// CircuitBreaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type CircuitState int
+
+const (
+	// StateClosed lets every request through, counting failures.
+	StateClosed CircuitState = iota
+	// StateOpen fails every request until the cool-down elapses.
+	StateOpen
+	// StateHalfOpen lets a few probe requests through, which decide whether
+	// the circuit closes or opens again.
+	StateHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+
+	return fmt.Sprintf("unknown(%d)", int(s))
+}
+
+// CircuitBreaker stops calling upstream once too many requests fail. 5xx
+// responses and transport errors count as failures; requests cancelled by
+// their caller don't count at all.
+type CircuitBreaker struct {
+	// FailureRatio opens the circuit once reached, provided at least
+	// MinRequests were seen in the current Interval.
+	FailureRatio float64
+	MinRequests  int
+	// Interval resets the counts of a closed circuit. Zero never resets them.
+	Interval time.Duration
+	// CoolDown is how long the circuit stays open before probing upstream.
+	CoolDown time.Duration
+	// HalfOpenRequests is the number of probes let through when half-open,
+	// 1 if not set. The circuit closes once they all succeed.
+	HalfOpenRequests int
+	// OnStateChange is called on every transition, e.g. to raise alerts. It
+	// must not use the breaker.
+	OnStateChange func(from, to CircuitState)
+
+	mu        sync.Mutex
+	state     CircuitState
+	requests  int
+	failures  int
+	probes    int
+	successes int
+	expiry    time.Time
+	now       func() time.Time
+}
+
+// NewCircuitBreaker opens after half of at least 10 requests failed within a
+// minute, and probes upstream again after 30 seconds.
+func NewCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureRatio:     0.5,
+		MinRequests:      10,
+		Interval:         time.Minute,
+		CoolDown:         30 * time.Second,
+		HalfOpenRequests: 1,
+		now:              time.Now,
+	}
+}
+
+// State returns the current state of the circuit.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.currentState(b.currentTime())
+}
+
+// currentTime falls back to time.Now for breakers built as literals.
+func (b *CircuitBreaker) currentTime() time.Time {
+	if b.now == nil {
+		return time.Now()
+	}
+
+	return b.now()
+}
+
+func (b *CircuitBreaker) halfOpenRequests() int {
+	if b.HalfOpenRequests <= 0 {
+		return 1
+	}
+
+	return b.HalfOpenRequests
+}
+
+// currentState applies the transitions due to time passing.
+func (b *CircuitBreaker) currentState(now time.Time) CircuitState {
+	if b.expiry.IsZero() || now.Before(b.expiry) {
+		return b.state
+	}
+
+	switch b.state {
+	case StateClosed:
+		b.reset(now)
+	case StateOpen:
+		b.setState(StateHalfOpen, now)
+	}
+
+	return b.state
+}
+
+func (b *CircuitBreaker) reset(now time.Time) {
+	b.requests, b.failures, b.probes, b.successes = 0, 0, 0, 0
+	b.expiry = time.Time{}
+
+	switch b.state {
+	case StateClosed:
+		if b.Interval > 0 {
+			b.expiry = now.Add(b.Interval)
+		}
+	case StateOpen:
+		b.expiry = now.Add(b.CoolDown)
+	}
+}
+
+func (b *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	if b.state == state {
+		return
+	}
+
+	from := b.state
+	b.state = state
+	b.reset(now)
+
+	if b.OnStateChange != nil {
+		b.OnStateChange(from, state)
+	}
+}
+
+// allow reports whether a request may be sent upstream.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.currentTime()
+
+	switch b.currentState(now) {
+	case StateOpen:
+		return fmt.Errorf("%w: retry in %s", ErrCircuitOpen, b.expiry.Sub(now).Round(time.Millisecond))
+	case StateHalfOpen:
+		if b.probes >= b.halfOpenRequests() {
+			return fmt.Errorf("%w: waiting for probe requests", ErrCircuitOpen)
+		}
+
+		b.probes++
+	case StateClosed:
+		if b.expiry.IsZero() && b.Interval > 0 {
+			b.expiry = now.Add(b.Interval)
+		}
+	}
+
+	return nil
+}
+
+// record counts the outcome of a request let through by allow.
+func (b *CircuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.currentTime()
+
+	switch b.currentState(now) {
+	case StateClosed:
+		b.requests++
+
+		if failed {
+			b.failures++
+		}
+
+		if b.requests >= b.MinRequests && float64(b.failures)/float64(b.requests) >= b.FailureRatio {
+			b.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		if failed {
+			b.setState(StateOpen, now)
+			return
+		}
+
+		b.successes++
+		if b.successes >= b.halfOpenRequests() {
+			b.setState(StateClosed, now)
+		}
+	}
+}
+
+// cancel releases the probe taken by a request whose outcome didn't count.
+func (b *CircuitBreaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.currentState(b.currentTime()) == StateHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// Transport is a Middleware failing fast with ErrCircuitOpen while the
+// circuit is open.
+func (b *CircuitBreaker) Transport(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := b.allow(); err != nil {
+			return nil, err
+		}
+
+		resp, err := next.RoundTrip(req)
+
+		switch {
+		case err != nil && req.Context().Err() != nil:
+			b.cancel()
+		case err != nil:
+			b.record(true)
+		default:
+			b.record(resp.StatusCode >= http.StatusInternalServerError)
+		}
+
+		return resp, err
+	})
+}
@@ -0,0 +1,180 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("CircuitBreaker", func() {
+	var (
+		server      *httptest.Server
+		requests    int32
+		status      int32
+		now         time.Time
+		breaker     *CircuitBreaker
+		transitions []string
+		form3Client Client
+		account     *api.Account
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&requests, 0)
+		atomic.StoreInt32(&status, http.StatusInternalServerError)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(int(atomic.LoadInt32(&status)))
+			_, _ = w.Write([]byte(`{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "version": 0}}`))
+		}))
+
+		now = time.Now()
+		transitions = nil
+
+		breaker = NewCircuitBreaker()
+		breaker.MinRequests = 4
+		breaker.now = func() time.Time { return now }
+		breaker.OnStateChange = func(from, to CircuitState) {
+			transitions = append(transitions, from.String()+" -> "+to.String())
+		}
+
+		form3Client = NewClient(WithBaseURL(server.URL), WithCircuitBreaker(breaker))
+		account = api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	fetch := func() error {
+		return form3Client.Fetch(context.TODO(), account)
+	}
+
+	It("should stay closed below the failure ratio", func() {
+		for i := 0; i < 4; i++ {
+			if i%2 == 0 {
+				atomic.StoreInt32(&status, http.StatusOK)
+			} else {
+				atomic.StoreInt32(&status, http.StatusNotFound)
+			}
+
+			_ = fetch()
+		}
+
+		Expect(breaker.State()).To(Equal(StateClosed))
+		Expect(transitions).To(BeEmpty())
+	})
+
+	It("should open on 5xx and fail fast", func() {
+		for i := 0; i < 4; i++ {
+			Expect(errors.Is(fetch(), ErrCircuitOpen)).To(BeFalse())
+		}
+
+		Expect(breaker.State()).To(Equal(StateOpen))
+
+		err := fetch()
+		Expect(errors.Is(err, ErrCircuitOpen)).To(BeTrue())
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(4)))
+		Expect(transitions).To(Equal([]string{"closed -> open"}))
+	})
+
+	It("should count transport errors as failures", func() {
+		server.Close()
+
+		for i := 0; i < 4; i++ {
+			_ = fetch()
+		}
+
+		Expect(breaker.State()).To(Equal(StateOpen))
+	})
+
+	It("should reset counts every interval", func() {
+		for i := 0; i < 3; i++ {
+			_ = fetch()
+		}
+
+		now = now.Add(2 * breaker.Interval)
+
+		_ = fetch()
+		Expect(breaker.State()).To(Equal(StateClosed))
+	})
+
+	It("should close once the probe succeeds after the cool-down", func() {
+		for i := 0; i < 4; i++ {
+			_ = fetch()
+		}
+
+		now = now.Add(breaker.CoolDown)
+		Expect(breaker.State()).To(Equal(StateHalfOpen))
+
+		atomic.StoreInt32(&status, http.StatusOK)
+		Expect(fetch()).To(Succeed())
+
+		Expect(breaker.State()).To(Equal(StateClosed))
+		Expect(transitions).To(Equal([]string{"closed -> open", "open -> half-open", "half-open -> closed"}))
+	})
+
+	It("should open again when the probe fails", func() {
+		for i := 0; i < 4; i++ {
+			_ = fetch()
+		}
+
+		now = now.Add(breaker.CoolDown)
+
+		_ = fetch()
+		Expect(breaker.State()).To(Equal(StateOpen))
+		Expect(errors.Is(fetch(), ErrCircuitOpen)).To(BeTrue())
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(5)))
+	})
+
+	It("should only let HalfOpenRequests probes through", func() {
+		for i := 0; i < 4; i++ {
+			_ = fetch()
+		}
+
+		now = now.Add(breaker.CoolDown)
+
+		Expect(breaker.allow()).To(Succeed())
+		Expect(errors.Is(breaker.allow(), ErrCircuitOpen)).To(BeTrue())
+
+		breaker.cancel()
+		Expect(breaker.allow()).To(Succeed())
+	})
+
+	It("should let one probe through when HalfOpenRequests isn't set", func() {
+		breaker.HalfOpenRequests = 0
+
+		for i := 0; i < 4; i++ {
+			_ = fetch()
+		}
+
+		now = now.Add(breaker.CoolDown)
+
+		atomic.StoreInt32(&status, http.StatusOK)
+		Expect(fetch()).To(Succeed())
+		Expect(breaker.State()).To(Equal(StateClosed))
+	})
+
+	It("should work when built as a literal", func() {
+		literal := &CircuitBreaker{FailureRatio: .5, MinRequests: 2, CoolDown: time.Millisecond}
+		form3Client = NewClient(WithBaseURL(server.URL), WithCircuitBreaker(literal))
+
+		Expect(literal.State()).To(Equal(StateClosed))
+
+		_ = fetch()
+		_ = fetch()
+		Expect(literal.State()).To(Equal(StateOpen))
+
+		atomic.StoreInt32(&status, http.StatusOK)
+		Eventually(fetch).Should(Succeed())
+		Expect(literal.State()).To(Equal(StateClosed))
+	})
+})
@@ -0,0 +1,88 @@
+package bulk
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Checkpoint records the rows an import is done with, so an interrupted
+// import can be resumed without creating them again. Rows are numbered from
+// 1. Failed rows aren't recorded, and are retried on resume.
+type Checkpoint interface {
+	Done(row int) bool
+	Mark(row int) error
+}
+
+// FileCheckpoint keeps the rows done in a file, one per line. Rows are
+// appended as they're marked, so the file survives the import being killed.
+type FileCheckpoint struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[int]bool
+}
+
+// NewFileCheckpoint opens the checkpoint at path, creating it if it doesn't
+// exist.
+func NewFileCheckpoint(path string) (*FileCheckpoint, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &FileCheckpoint{file: file, done: map[int]bool{}}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		row, err := strconv.Atoi(line)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+		}
+
+		checkpoint.done[row] = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return checkpoint, nil
+}
+
+func (c *FileCheckpoint) Done(row int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.done[row]
+}
+
+func (c *FileCheckpoint) Mark(row int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.done[row] {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(c.file, row); err != nil {
+		return err
+	}
+
+	c.done[row] = true
+
+	return nil
+}
+
+func (c *FileCheckpoint) Close() error {
+	return c.file.Close()
+}
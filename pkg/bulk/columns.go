@@ -0,0 +1,146 @@
+package bulk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var ErrUnknownColumn = errors.New("unknown column")
+
+// DefaultColumns are the fields written to CSV when none are selected, which
+// are enough to create the accounts again.
+var DefaultColumns = []string{
+	"id",
+	"type",
+	"organisation_id",
+	"attributes.country",
+	"attributes.base_currency",
+	"attributes.bank_id",
+	"attributes.bank_id_code",
+	"attributes.account_number",
+	"attributes.iban",
+	"attributes.bic",
+	"attributes.name",
+	"attributes.account_classification",
+	"attributes.status",
+}
+
+// fieldType returns the type of the field found at the JSON path of t, e.g.
+// attributes.bank_id.
+func fieldType(t reflect.Type, path []string) (reflect.Type, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if len(path) == 0 {
+		return t, true
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		if field.Anonymous && name == "" {
+			if embedded, ok := fieldType(field.Type, path); ok {
+				return embedded, true
+			}
+
+			continue
+		}
+
+		if name == path[0] {
+			return fieldType(field.Type, path[1:])
+		}
+	}
+
+	return nil, false
+}
+
+// columnTypes resolves the type of every column in t.
+func columnTypes(t reflect.Type, columns []string) ([]reflect.Type, error) {
+	types := make([]reflect.Type, 0, len(columns))
+
+	for _, column := range columns {
+		columnType, ok := fieldType(t, strings.Split(column, "."))
+		if !ok {
+			return nil, fmt.Errorf("%w %s for %s", ErrUnknownColumn, column, t)
+		}
+
+		types = append(types, columnType)
+	}
+
+	return types, nil
+}
+
+// cell formats a JSON value for CSV. Strings are written as they are, other
+// values as JSON.
+func cell(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+
+	body, err := json.Marshal(value)
+
+	return string(body), err
+}
+
+// lookup returns the value at path in a decoded JSON object.
+func lookup(value map[string]interface{}, path []string) interface{} {
+	var current interface{} = value
+
+	for _, key := range path {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		current = object[key]
+	}
+
+	return current
+}
+
+// parseCell turns a CSV cell into the JSON value of a field of type t.
+// Empty cells are left out.
+func parseCell(text string, t reflect.Type) (json.RawMessage, bool, error) {
+	if text == "" {
+		return nil, false, nil
+	}
+
+	if t.Kind() == reflect.String {
+		body, err := json.Marshal(text)
+		return body, true, err
+	}
+
+	if !json.Valid([]byte(text)) {
+		return nil, false, fmt.Errorf("invalid %s value %q", t, text)
+	}
+
+	return json.RawMessage(text), true, nil
+}
+
+// set stores value at path in a JSON object, creating the objects on the
+// way.
+func set(object map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := object[key].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			object[key] = child
+		}
+
+		object = child
+	}
+
+	object[path[len(path)-1]] = value
+}
@@ -0,0 +1,173 @@
+// Package bulk moves resources between organisations and environments: it
+// exports them to NDJSON or CSV files, and imports those files back.
+package bulk
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+)
+
+type Format string
+
+const (
+	// FormatNDJSON writes a JSON object per line, with every field.
+	FormatNDJSON Format = "ndjson"
+	// FormatCSV writes the selected columns, after a header naming them.
+	FormatCSV Format = "csv"
+)
+
+// FormatOf guesses the format of a file from its extension.
+func FormatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	case ".csv":
+		return FormatCSV, nil
+	}
+
+	return "", fmt.Errorf("unknown format of %s, expected .ndjson, .jsonl or .csv", path)
+}
+
+// resolve validates f, which defaults to NDJSON.
+func (f Format) resolve() (Format, error) {
+	switch f {
+	case "":
+		return FormatNDJSON, nil
+	case FormatNDJSON, FormatCSV:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("unknown format %q, expected %s or %s", f, FormatNDJSON, FormatCSV)
+}
+
+type ExportOptions struct {
+	// Format is NDJSON if not set.
+	Format Format
+	// Columns are the JSON paths of the fields written to CSV, e.g.
+	// attributes.bank_id. DefaultColumns are used if empty.
+	Columns     []string
+	ListOptions *pkg.ListOptions
+}
+
+// itemType returns the type of the items of list.
+func itemType(list api.Object) (reflect.Type, error) {
+	v, err := api.EnforcePtr(list)
+	if err != nil {
+		return nil, err
+	}
+
+	items := v.FieldByName("Items")
+	if !items.IsValid() {
+		return nil, pkg.ErrInvalidObjectType
+	}
+
+	return items.Type().Elem(), nil
+}
+
+// Export streams every resource listed through list, e.g. &api.AccountList{},
+// to w, fetching a page at a time. It returns the number of resources
+// written.
+func Export(ctx context.Context, client pkg.Client, w io.Writer, list api.Object, opts *ExportOptions) (int, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+
+	format, err := opts.Format.resolve()
+	if err != nil {
+		return 0, err
+	}
+
+	t, err := itemType(list)
+	if err != nil {
+		return 0, err
+	}
+
+	var write func(api.Object) error
+
+	switch format {
+	case FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		write = func(obj api.Object) error { return encoder.Encode(obj) }
+	case FormatCSV:
+		if write, err = csvWriter(w, t, opts.Columns); err != nil {
+			return 0, err
+		}
+	}
+
+	it := client.ListIter(list, opts.ListOptions)
+	count := 0
+
+	for it.Next(ctx) {
+		if err := write(it.Item()); err != nil {
+			return count, err
+		}
+
+		count++
+	}
+
+	return count, it.Err()
+}
+
+// csvWriter writes the header right away, and returns a function writing a
+// row per object.
+func csvWriter(w io.Writer, t reflect.Type, columns []string) (func(api.Object) error, error) {
+	if len(columns) == 0 {
+		columns = DefaultColumns
+	}
+
+	if _, err := columnTypes(t, columns); err != nil {
+		return nil, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+
+	paths := make([][]string, 0, len(columns))
+	for _, column := range columns {
+		paths = append(paths, strings.Split(column, "."))
+	}
+
+	return func(obj api.Object) error {
+		body, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+
+		var value map[string]interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			return err
+		}
+
+		row := make([]string, 0, len(paths))
+
+		for _, path := range paths {
+			text, err := cell(lookup(value, path))
+			if err != nil {
+				return err
+			}
+
+			row = append(row, text)
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+
+		// Flushing every row keeps the output streamed, and reports write
+		// errors as soon as they happen.
+		writer.Flush()
+
+		return writer.Error()
+	}, nil
+}
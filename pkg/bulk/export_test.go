@@ -0,0 +1,112 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+const organisationID = "721763e9-b2e2-4ebb-8de9-b440e3cf23a6"
+
+var accountIDs = []string{
+	"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+	"93bfaa94-9e48-402d-9744-6ef85c6303b0",
+	"9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5",
+}
+
+func newAccount(id string) *api.Account {
+	account := api.NewAccount(id, 0)
+	account.Type = "accounts"
+	account.OrganisationID = organisationID
+	account.Attributes = api.AccountAttributes{
+		Country:               "GB",
+		BaseCurrency:          "GBP",
+		BankID:                "400300",
+		BankIDCode:            "GBDSC",
+		BIC:                   "NWBKGB22",
+		AccountClassification: api.AccountClassificationPersonal,
+	}
+
+	return account
+}
+
+var _ = Describe("export", func() {
+	var (
+		server *fakeapi.Server
+		client pkg.Client
+		out    *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		server = fakeapi.NewServer()
+		client = pkg.NewClient(pkg.WithBaseURL(server.URL))
+		out = &bytes.Buffer{}
+
+		for _, id := range accountIDs {
+			Expect(server.Seed(newAccount(id))).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should stream every page as NDJSON", func() {
+		count, err := Export(context.TODO(), client, out, &api.AccountList{}, &ExportOptions{
+			Format:      FormatNDJSON,
+			ListOptions: &pkg.ListOptions{PageSize: 2},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(3))
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(HaveLen(3))
+
+		account := &api.Account{}
+		Expect(json.Unmarshal([]byte(lines[2]), account)).To(Succeed())
+		Expect(account.ID).To(Equal(accountIDs[2]))
+		Expect(account.Attributes.BankID).To(Equal("400300"))
+	})
+
+	It("should write the selected columns as CSV", func() {
+		_, err := Export(context.TODO(), client, out, &api.AccountList{}, &ExportOptions{
+			Format:  FormatCSV,
+			Columns: []string{"id", "version", "attributes.bank_id", "attributes.account_number"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(Equal(
+			"id,version,attributes.bank_id,attributes.account_number\n" +
+				accountIDs[0] + ",0,400300,\n" +
+				accountIDs[1] + ",0,400300,\n" +
+				accountIDs[2] + ",0,400300,\n"))
+	})
+
+	It("should reject unknown columns and formats", func() {
+		_, err := Export(context.TODO(), client, out, &api.AccountList{}, &ExportOptions{
+			Format:  FormatCSV,
+			Columns: []string{"id", "attributes.unknown"},
+		})
+		Expect(errors.Is(err, ErrUnknownColumn)).To(BeTrue())
+		Expect(out.String()).To(BeEmpty())
+
+		_, err = Export(context.TODO(), client, out, &api.AccountList{}, &ExportOptions{Format: "xml"})
+		Expect(err).To(MatchError(`unknown format "xml", expected ndjson or csv`))
+	})
+
+	It("should guess the format of files", func() {
+		Expect(FormatOf("accounts.CSV")).To(Equal(FormatCSV))
+		Expect(FormatOf("accounts.jsonl")).To(Equal(FormatNDJSON))
+
+		_, err := FormatOf("accounts.xml")
+		Expect(err).To(HaveOccurred())
+	})
+})
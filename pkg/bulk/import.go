@@ -0,0 +1,372 @@
+package bulk
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var ErrImportFailed = errors.New("import failed")
+
+const DefaultParallelism = 4
+
+// maxLine bounds the size of an NDJSON line.
+const maxLine = 1024 * 1024
+
+type ImportOptions struct {
+	// Format is NDJSON if not set.
+	Format Format
+	// Parallelism is the number of rows created at once, DefaultParallelism
+	// if not set.
+	Parallelism int
+	// OrganisationID replaces the organisation of every row, e.g. to copy
+	// accounts from one organisation to another.
+	OrganisationID string
+	// Checkpoint skips the rows done by a previous import, and records the
+	// ones done by this one.
+	Checkpoint Checkpoint
+	// SkipExisting counts the rows which already exist as skipped, instead of
+	// failed.
+	SkipExisting bool
+}
+
+// RowError is a row which couldn't be imported. ID is empty if the row
+// couldn't be decoded.
+type RowError struct {
+	Row int
+	ID  string
+	Err error
+}
+
+func (e *RowError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+	}
+
+	return fmt.Sprintf("row %d (%s): %s", e.Row, e.ID, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportReport counts the rows created, and the ones skipped because they
+// were checkpointed or already existed. Errors are sorted by row.
+type ImportReport struct {
+	Created int
+	Skipped int
+	Errors  []*RowError
+}
+
+// WriteErrors writes the errors as CSV, with a row, id and error column.
+func (r *ImportReport) WriteErrors(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"row", "id", "error"}); err != nil {
+		return err
+	}
+
+	for _, rowErr := range r.Errors {
+		if err := writer.Write([]string{strconv.Itoa(rowErr.Row), rowErr.ID, rowErr.Err.Error()}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+type row struct {
+	number int
+	fields map[string]interface{}
+	err    error
+}
+
+// rowReader returns the next row, or io.EOF once done. Rows which can't be
+// decoded are returned with an error, and reading carries on.
+type rowReader func() (*row, error)
+
+func ndjsonReader(r io.Reader) rowReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLine)
+
+	number := 0
+
+	return func() (*row, error) {
+		for scanner.Scan() {
+			number++
+
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			result := &row{number: number}
+			result.err = json.Unmarshal([]byte(line), &result.fields)
+
+			return result, nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, io.EOF
+	}
+}
+
+// csvReader reads the header naming the columns, which must be fields of t.
+func csvReader(r io.Reader, t reflect.Type) (rowReader, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return func() (*row, error) { return nil, io.EOF }, nil
+		}
+
+		return nil, err
+	}
+
+	types, err := columnTypes(t, header)
+	if err != nil {
+		return nil, err
+	}
+
+	number := 0
+
+	return func() (*row, error) {
+		record, err := reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+		}
+
+		number++
+
+		if err != nil {
+			return &row{number: number, err: err}, nil
+		}
+
+		result := &row{number: number, fields: map[string]interface{}{}}
+
+		for i, text := range record {
+			value, ok, err := parseCell(text, types[i])
+			if err != nil {
+				result.err = fmt.Errorf("%s: %w", header[i], err)
+				break
+			}
+
+			if ok {
+				set(result.fields, strings.Split(header[i], "."), value)
+			}
+		}
+
+		return result, nil
+	}, nil
+}
+
+// decode builds the object of kind described by a row.
+func decode(kind string, fields map[string]interface{}, organisationID string) (api.Object, error) {
+	if organisationID != "" {
+		fields["organisation_id"] = organisationID
+	}
+
+	// Imported resources are new ones, whatever version they were exported
+	// at.
+	delete(fields, "version")
+
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := api.Schema.NewObj(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+type importer struct {
+	client pkg.Client
+	kind   string
+	opts   *ImportOptions
+
+	mu     sync.Mutex
+	report *ImportReport
+}
+
+func (i *importer) fail(number int, id string, err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.report.Errors = append(i.report.Errors, &RowError{Row: number, ID: id, Err: err})
+}
+
+func (i *importer) done(number int, created bool) {
+	if i.opts.Checkpoint != nil {
+		if err := i.opts.Checkpoint.Mark(number); err != nil {
+			i.fail(number, "", fmt.Errorf("checkpoint: %w", err))
+			return
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if created {
+		i.report.Created++
+	} else {
+		i.report.Skipped++
+	}
+}
+
+func (i *importer) create(ctx context.Context, r *row) {
+	if r.err != nil {
+		i.fail(r.number, "", r.err)
+		return
+	}
+
+	obj, err := decode(i.kind, r.fields, i.opts.OrganisationID)
+	if err != nil {
+		i.fail(r.number, "", err)
+		return
+	}
+
+	err = i.client.Create(ctx, obj)
+
+	switch {
+	case err == nil:
+		i.done(r.number, true)
+	case i.opts.SkipExisting && errors.Is(err, pkg.ErrConflict):
+		i.done(r.number, false)
+	default:
+		i.fail(r.number, obj.GetID(), err)
+	}
+}
+
+// Import creates a resource of kind, e.g. api.Account, per row read from r,
+// as written by Export. Rows are created concurrently, and every row is
+// attempted even if some fail: their errors are reported in the report, and
+// Import then returns ErrImportFailed. Other errors, e.g. an unknown CSV
+// column or a cancelled context, stop the import.
+func Import(ctx context.Context, client pkg.Client, r io.Reader, kind string, opts *ImportOptions) (*ImportReport, error) {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+
+	format, err := opts.Format.resolve()
+	if err != nil {
+		return &ImportReport{}, err
+	}
+
+	obj, err := api.Schema.NewObj(kind)
+	if err != nil {
+		return &ImportReport{}, err
+	}
+
+	var next rowReader
+
+	switch format {
+	case FormatNDJSON:
+		next = ndjsonReader(r)
+	case FormatCSV:
+		if next, err = csvReader(r, reflect.TypeOf(obj)); err != nil {
+			return &ImportReport{}, err
+		}
+	}
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+
+	i := &importer{client: client, kind: kind, opts: opts, report: &ImportReport{}}
+	rows := make(chan *row)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for r := range rows {
+				i.create(ctx, r)
+			}
+		}()
+	}
+
+	total, err := i.feed(ctx, next, rows)
+
+	close(rows)
+	wg.Wait()
+
+	report := i.report
+	sort.Slice(report.Errors, func(a, b int) bool { return report.Errors[a].Row < report.Errors[b].Row })
+
+	if err != nil {
+		return report, err
+	}
+
+	if len(report.Errors) > 0 {
+		return report, fmt.Errorf("%w: %d of %d rows", ErrImportFailed, len(report.Errors), total)
+	}
+
+	return report, nil
+}
+
+// feed sends the rows which aren't checkpointed to the workers, and returns
+// the number of rows read.
+func (i *importer) feed(ctx context.Context, next rowReader, rows chan<- *row) (int, error) {
+	total := 0
+
+	for {
+		r, err := next()
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+
+		if err != nil {
+			return total, err
+		}
+
+		total++
+
+		if i.opts.Checkpoint != nil && i.opts.Checkpoint.Done(r.number) {
+			i.mu.Lock()
+			i.report.Skipped++
+			i.mu.Unlock()
+
+			continue
+		}
+
+		select {
+		case rows <- r:
+		case <-ctx.Done():
+			return total, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fake"
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+var _ = Describe("import", func() {
+	const otherOrganisationID = "0f1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+
+	var (
+		source *fakeapi.Server
+		target *fakeapi.Server
+		client pkg.Client
+	)
+
+	export := func(format Format) *bytes.Buffer {
+		out := &bytes.Buffer{}
+		_, err := Export(context.TODO(), pkg.NewClient(pkg.WithBaseURL(source.URL)), out, &api.AccountList{}, &ExportOptions{Format: format})
+		Expect(err).NotTo(HaveOccurred())
+
+		return out
+	}
+
+	imported := func() []api.Account {
+		list := &api.AccountList{}
+		Expect(client.List(context.TODO(), list, nil)).To(Succeed())
+
+		// Rows are created concurrently, in no particular order.
+		sort.Slice(list.Items, func(i, j int) bool {
+			return list.Items[i].ID > list.Items[j].ID
+		})
+
+		return list.Items
+	}
+
+	BeforeEach(func() {
+		source = fakeapi.NewServer()
+		target = fakeapi.NewServer()
+		client = pkg.NewClient(pkg.WithBaseURL(target.URL))
+
+		for _, id := range accountIDs {
+			account := newAccount(id)
+			Expect(source.Seed(account)).To(Succeed())
+
+			// Exported accounts have moved on from their first version.
+			account.Attributes.CustomerID = "1234"
+			Expect(pkg.NewClient(pkg.WithBaseURL(source.URL)).Update(context.TODO(), account)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		source.Close()
+		target.Close()
+	})
+
+	for _, format := range []Format{FormatNDJSON, FormatCSV} {
+		format := format
+
+		It("should import what was exported as "+string(format), func() {
+			report, err := Import(context.TODO(), client, export(format), "api.Account", &ImportOptions{
+				Format:         format,
+				OrganisationID: otherOrganisationID,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Created).To(Equal(3))
+
+			accounts := imported()
+			Expect(accounts).To(HaveLen(3))
+			Expect(accounts[0].ID).To(Equal(accountIDs[0]))
+			Expect(accounts[0].Version).To(Equal(0))
+			Expect(accounts[0].OrganisationID).To(Equal(otherOrganisationID))
+			Expect(accounts[0].Attributes.BIC).To(Equal(api.BIC("NWBKGB22")))
+		})
+	}
+
+	It("should report the rows which failed and carry on", func() {
+		rows := strings.Split(export(FormatCSV).String(), "\n")
+		rows[2] = strings.Replace(rows[2], ",GB,", ",XX,", 1)
+		rows = append(rows[:3], append([]string{"too,few,columns"}, rows[3:]...)...)
+
+		report, err := Import(context.TODO(), client, strings.NewReader(strings.Join(rows, "\n")), "api.Account", &ImportOptions{Format: FormatCSV})
+		Expect(errors.Is(err, ErrImportFailed)).To(BeTrue())
+		Expect(err).To(MatchError("import failed: 2 of 4 rows"))
+		Expect(report.Created).To(Equal(2))
+		Expect(report.Errors).To(HaveLen(2))
+		Expect(report.Errors[0].Row).To(Equal(2))
+		Expect(report.Errors[0].ID).To(Equal(accountIDs[1]))
+		Expect(errors.As(report.Errors[0], &api.ValidationErrors{})).To(BeTrue())
+		Expect(report.Errors[1].Row).To(Equal(3))
+		Expect(report.Errors[1].ID).To(BeEmpty())
+
+		out := &bytes.Buffer{}
+		Expect(report.WriteErrors(out)).To(Succeed())
+		Expect(out.String()).To(HavePrefix("row,id,error\n2," + accountIDs[1] + ",validation failed: "))
+	})
+
+	It("should reject unknown columns", func() {
+		_, err := Import(context.TODO(), client, strings.NewReader("id,attributes.unknown\n"), "api.Account", &ImportOptions{Format: FormatCSV})
+		Expect(errors.Is(err, ErrUnknownColumn)).To(BeTrue())
+	})
+
+	It("should skip existing resources", func() {
+		Expect(target.Seed(newAccount(accountIDs[1]))).To(Succeed())
+
+		report, err := Import(context.TODO(), client, export(FormatNDJSON), "api.Account", &ImportOptions{SkipExisting: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Created).To(Equal(2))
+		Expect(report.Skipped).To(Equal(1))
+	})
+
+	It("should resume from a checkpoint", func() {
+		dir, err := ioutil.TempDir("", "bulk")
+		Expect(err).NotTo(HaveOccurred())
+
+		defer os.RemoveAll(dir)
+
+		ndjson := export(FormatNDJSON).String()
+		path := filepath.Join(dir, "checkpoint")
+
+		checkpoint, err := NewFileCheckpoint(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The first row is created, and the import dies before the others.
+		firstRow := strings.SplitAfter(ndjson, "\n")[0]
+		_, err = Import(context.TODO(), client, strings.NewReader(firstRow), "api.Account", &ImportOptions{Checkpoint: checkpoint})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(checkpoint.Close()).To(Succeed())
+
+		checkpoint, err = NewFileCheckpoint(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		defer checkpoint.Close()
+
+		report, err := Import(context.TODO(), client, strings.NewReader(ndjson), "api.Account", &ImportOptions{Checkpoint: checkpoint})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Created).To(Equal(2))
+		Expect(report.Skipped).To(Equal(1))
+		Expect(imported()).To(HaveLen(3))
+
+		Expect(ioutil.ReadFile(path)).To(WithTransform(func(body []byte) []string {
+			return strings.Fields(string(body))
+		}, ConsistOf("1", "2", "3")))
+	})
+
+	It("should create rows concurrently, up to the parallelism", func() {
+		var (
+			mu      sync.Mutex
+			running int
+			peak    int
+		)
+
+		client := fake.NewClient()
+		client.PrependReactor(fake.VerbCreate, "api.Account", func(fake.Action) (bool, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+
+			return false, nil
+		})
+
+		report, err := Import(context.TODO(), client, export(FormatNDJSON), "api.Account", &ImportOptions{Parallelism: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Created).To(Equal(3))
+		Expect(peak).To(Equal(2))
+	})
+})
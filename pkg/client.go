@@ -3,113 +3,63 @@ package pkg
 import (
 	"bytes"
 	"context"
+	"crypto/rsa"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"reflect"
-	"strings"
+	"sync"
 
 	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/auth"
 )
 
 type Client interface {
 	Fetch(context.Context, api.Object) error
 	List(context.Context, api.Object, *ListOptions) error
+	Pager(api.Object, *ListOptions) *Pager
+	ListIter(api.Object, *ListOptions) *ListIter
 	Create(context.Context, api.Object) error
+	Update(context.Context, api.Object) error
 	Delete(context.Context, api.Object) error
+	Apply(context.Context, []api.Object, *ApplyOptions) ([]*ApplyResult, error)
+	BatchCreate(context.Context, []api.Object, *BatchOptions) ([]*BatchResult, error)
+	BatchDelete(context.Context, []api.Object, *BatchOptions) ([]*BatchResult, error)
+	BatchFetch(context.Context, []api.Object, *BatchOptions) ([]*BatchResult, error)
+
+	Accounts() *AccountsClient
+	Payments() *PaymentsClient
+	Subscriptions() *SubscriptionsClient
 }
 
 type Form3Client struct {
-	BaseURL string
-	Version string
-}
-
-type ListFilter struct {
-	BankIDCode    string
-	BankID        string
-	AccountNumber string
-	IBAN          string
-	CustomerID    string
-	Country       string
-}
-
-func (l *ListFilter) Build() string {
-	query := ""
-
-	if l.BankIDCode != "" {
-		query = fmt.Sprintf("%s&filter[bank_id_code]=%s", query, l.BankIDCode)
-	}
-
-	if l.BankID != "" {
-		query = fmt.Sprintf("%s&filter[bank_id]=%s", query, l.BankID)
-	}
-
-	if l.AccountNumber != "" {
-		query = fmt.Sprintf("%s&filter[account_number]=%s", query, l.AccountNumber)
-	}
+	BaseURL     string
+	Version     string
+	RetryPolicy *RetryPolicy
+	RateLimiter *RateLimiter
 
-	if l.IBAN != "" {
-		query = fmt.Sprintf("%s&filter[iban]=%s", query, l.IBAN)
-	}
+	// SkipValidation sends objects upstream without running their Validate
+	// hook first.
+	SkipValidation bool
 
-	if l.CustomerID != "" {
-		query = fmt.Sprintf("%s&filter[customer_id]=%s", query, l.IBAN)
-	}
+	HTTPClient  *http.Client
+	Transport   http.RoundTripper
+	Middlewares []Middleware
 
-	if l.Country != "" {
-		query = fmt.Sprintf("%s&filter[country]=%s", query, l.IBAN)
-	}
-
-	return query
+	client         *http.Client
+	httpClientOnce sync.Once
 }
 
 type ListOptions struct {
 	PageNumber int
 	PageSize   int
-	Filter     *ListFilter
-}
-
-func (l *ListOptions) Build() string {
-	query := "?"
-
-	if l.PageNumber != 0 {
-		query = fmt.Sprintf("%s&page[number]=%d", query, l.PageNumber)
-	}
-
-	if l.PageSize != 0 {
-		query = fmt.Sprintf("%s&page[size]=%d", query, l.PageSize)
-	}
+	Filter     *Filter
 
-	if l.Filter != nil {
-		query = fmt.Sprintf("%s%s", query, l.Filter.Build())
-	}
-
-	return query
-}
-
-// TODO: implement retry/backoff
-
-// TODO: handle all errors from upstream
-
-var RespErrors = map[int]string{
-	http.StatusBadRequest:          "invalid request: %s",
-	http.StatusUnauthorized:        "not authorized: %s",
-	http.StatusNotFound:            "not found: %s",
-	http.StatusInternalServerError: "server error %s",
-	http.StatusBadGateway:          "bad gateway %s",
-	http.StatusGatewayTimeout:      "gateway timeout %s",
-}
-
-var ErrInvalidObjectType = errors.New("invalid object type")
-
-const MissingOrInvalidArgumentFmt = "missing or invalid argument: %s"
-const DefaultResponseErrorFmt = "error: %s"
-
-type errorResponse struct {
-	ErrorMessage string `json:"error_message"`
+	// Cursor resumes listing from a page returned by Pager.Cursor, ignoring
+	// every other option.
+	Cursor string
 }
 
 func (c *Form3Client) baseURL() string {
@@ -125,25 +75,34 @@ func (c *Form3Client) err(resp *http.Response) error {
 		return nil
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf(DefaultResponseErrorFmt, "couldn't read response from server")
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Attempts:   attemptOf(resp),
 	}
 
-	errMsg := ""
-	var errResponse = &errorResponse{}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.URL = resp.Request.URL.String()
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		apiErr.ErrorMessage = fmt.Sprintf("couldn't read response from server: %s", err)
+		apiErr.Err = err
 
-	err = json.Unmarshal(body, errResponse)
-	if err == nil {
-		errMsg = errResponse.ErrorMessage
+		return apiErr
 	}
 
-	respError, exists := RespErrors[resp.StatusCode]
-	if !exists {
-		return fmt.Errorf(DefaultResponseErrorFmt, body)
+	apiErr.Body = body
+
+	var errResponse = &errorResponse{}
+	if err := json.Unmarshal(body, errResponse); err == nil {
+		apiErr.ErrorMessage = errResponse.ErrorMessage
+		apiErr.ErrorCode = errResponse.ErrorCode
 	}
 
-	return fmt.Errorf(respError, errMsg)
+	return apiErr
 }
 
 func (c *Form3Client) execute(ctx context.Context, method, url string, body io.Reader) (*http.Response, error) {
@@ -154,8 +113,21 @@ func (c *Form3Client) execute(ctx context.Context, method, url string, body io.R
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	if c.RetryPolicy != nil && isIdempotent(method) {
+		return c.doWithRetry(ctx, req)
+	}
+
+	return c.do(req)
+}
+
+func (c *Form3Client) do(req *http.Request) (*http.Response, error) {
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.Wait(req.Context(), req.Method); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -164,15 +136,11 @@ func (c *Form3Client) execute(ctx context.Context, method, url string, body io.R
 }
 
 func (c *Form3Client) url(obj api.Object) (string, error) {
-	endpoint, err := api.Schema.GetEndpointForObj(obj)
+	endpoint, err := api.Schema.ObjectPath(obj)
 	if err != nil {
 		return "", err
 	}
 
-	if strings.Contains(endpoint, "%s") {
-		endpoint = fmt.Sprintf(endpoint, obj.GetID())
-	}
-
 	url := fmt.Sprintf("%s/%s", c.baseURL(), endpoint)
 
 	return url, nil
@@ -207,107 +175,106 @@ func (c *Form3Client) Fetch(ctx context.Context, obj api.Object) error {
 }
 
 func (c *Form3Client) List(ctx context.Context, obj api.Object, listOptions *ListOptions) error {
+	pager := c.Pager(obj, listOptions)
+	if err := pager.Err(); err != nil {
+		return err
+	}
+
 	v, err := api.EnforcePtr(obj)
 	if err != nil {
 		return err
 	}
 
 	items := v.FieldByName("Items")
-	if !items.IsValid() {
-		return ErrInvalidObjectType
-	}
+	original := reflect.ValueOf(items.Interface())
 
-	url, err := c.url(obj)
-	if err != nil {
-		return err
+	results := reflect.MakeSlice(items.Type(), 0, 1)
+
+	for pager.Next(ctx) {
+		results = reflect.AppendSlice(results, items)
 	}
 
-	if listOptions != nil {
-		url = fmt.Sprintf("%s%s", url, listOptions.Build())
+	if err := pager.Err(); err != nil {
+		items.Set(original)
+		return err
 	}
 
-	objListType := reflect.StructOf([]reflect.StructField{
-		{
-			Name: "Data",
-			Type: items.Type(),
-			Tag:  `json:"data"`,
-		},
-		{
-			Name: "Links",
-			Type: reflect.TypeOf(api.Links{}),
-			Tag:  `json:"links"`,
-		},
-	})
-	objList := reflect.New(objListType).Elem()
+	items.Set(results)
 
-	results := reflect.MakeSlice(items.Type(), 0, 1)
+	return nil
+}
 
-	for {
-		resp, err := c.execute(ctx, http.MethodGet, url, nil)
-		if err != nil {
-			return err
-		}
+func (c *Form3Client) validate(obj api.Object) error {
+	if c.SkipValidation {
+		return nil
+	}
 
-		defer resp.Body.Close()
+	return api.Schema.Validate(obj)
+}
 
-		if !c.isOK(resp) {
-			return c.err(resp)
-		}
+func (c *Form3Client) Create(ctx context.Context, obj api.Object) error {
+	if err := c.validate(obj); err != nil {
+		return err
+	}
 
-		result := objList.Addr().Interface()
+	dataObj := api.WrapObject(obj)
 
-		bodyBytes, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
+	jsonObj, err := json.Marshal(dataObj)
+	if err != nil {
+		return err
+	}
 
-		if err := json.Unmarshal(bodyBytes, result); err != nil {
-			return err
-		}
+	endpoint, err := api.Schema.CollectionPath(obj)
+	if err != nil {
+		return err
+	}
 
-		data := objList.FieldByName("Data")
-		store := reflect.MakeSlice(items.Type(), data.Len(), data.Len()+1)
+	url := fmt.Sprintf("%s/%s", c.baseURL(), endpoint)
+	resp, err := c.execute(ctx, http.MethodPost, url, bytes.NewBuffer(jsonObj))
+	if err != nil {
+		return err
+	}
 
-		for i := 0; i < data.Len(); i++ {
-			dest := store.Index(i)
-			item := data.Index(i).Interface().(api.Object)
-			dest.Set(reflect.ValueOf(item))
-		}
+	defer resp.Body.Close()
 
-		results = reflect.AppendSlice(results, store)
+	if !c.isOK(resp) {
+		return c.err(resp)
+	}
 
-		links := objList.FieldByName("Links").Interface().(api.Links)
-		if links.Next == "" || links.Next == links.Self {
-			break
-		}
+	// The created object, with the fields set upstream, is written back.
+	return json.NewDecoder(resp.Body).Decode(&dataObj)
+}
 
-		url = fmt.Sprintf("%s/%s", c.BaseURL, links.Next)
+func (c *Form3Client) Update(ctx context.Context, obj api.Object) error {
+	if _, err := api.EnforcePtr(obj); err != nil {
+		return err
 	}
 
-	items.Set(results)
-
-	return nil
-}
+	if obj.GetID() == "" {
+		return fmt.Errorf(MissingOrInvalidArgumentFmt, "ID")
+	}
 
-func (c *Form3Client) Create(ctx context.Context, obj api.Object) error {
-	dataObj := api.WrapObject(obj)
+	if obj.GetVersion() < 0 {
+		return fmt.Errorf(MissingOrInvalidArgumentFmt, "Version")
+	}
 
-	jsonObj, err := json.Marshal(dataObj)
-	if err != nil {
+	if err := c.validate(obj); err != nil {
 		return err
 	}
 
-	endpoint, err := api.Schema.GetEndpointForObj(obj)
+	url, err := c.url(obj)
 	if err != nil {
 		return err
 	}
 
-	if strings.HasSuffix(endpoint, "%s") {
-		endpoint = endpoint[:len(endpoint)-2]
+	dataObj := api.WrapObject(obj)
+
+	jsonObj, err := json.Marshal(dataObj)
+	if err != nil {
+		return err
 	}
 
-	url := fmt.Sprintf("%s/%s", c.baseURL(), endpoint)
-	resp, err := c.execute(ctx, http.MethodPost, url, bytes.NewBuffer(jsonObj))
+	resp, err := c.execute(ctx, http.MethodPatch, url, bytes.NewBuffer(jsonObj))
 	if err != nil {
 		return err
 	}
@@ -315,27 +282,16 @@ func (c *Form3Client) Create(ctx context.Context, obj api.Object) error {
 	defer resp.Body.Close()
 
 	if !c.isOK(resp) {
-		return c.err(resp)
-	}
+		respErr := c.err(resp)
 
-	objListType := reflect.StructOf([]reflect.StructField{
-		{
-			Name: "Data",
-			Type: reflect.TypeOf(obj),
-			Tag:  `json:"data"`,
-		},
-	})
-	objList := reflect.New(objListType).Elem()
-	result := objList.Addr().Interface()
+		if apiErr, ok := respErr.(*APIError); ok && apiErr.StatusCode == http.StatusConflict {
+			return &VersionConflictError{Version: obj.GetVersion(), Err: apiErr}
+		}
 
-	parsedErr := json.NewDecoder(resp.Body).Decode(result)
-	if parsedErr != nil {
-		return parsedErr
+		return respErr
 	}
 
-	reflect.ValueOf(&obj).Elem().Set(objList.FieldByName("Data"))
-
-	return nil
+	return json.NewDecoder(resp.Body).Decode(&dataObj)
 }
 
 func (c *Form3Client) Delete(ctx context.Context, obj api.Object) error {
@@ -367,6 +323,18 @@ func (c *Form3Client) Delete(ctx context.Context, obj api.Object) error {
 	return nil
 }
 
+func (c *Form3Client) Accounts() *AccountsClient {
+	return NewTypedClient[api.Account, api.AccountList](c)
+}
+
+func (c *Form3Client) Payments() *PaymentsClient {
+	return NewTypedClient[api.Payment, api.PaymentList](c)
+}
+
+func (c *Form3Client) Subscriptions() *SubscriptionsClient {
+	return NewTypedClient[api.Subscription, api.SubscriptionList](c)
+}
+
 type Option func(*Form3Client)
 
 func WithBaseURL(baseURL string) Option {
@@ -381,6 +349,72 @@ func WithVersion(version string) Option {
 	}
 }
 
+// WithRetryPolicy retries idempotent requests (Fetch, List and Delete) that
+// fail with a transient error, according to the given policy.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(client *Form3Client) {
+		client.RetryPolicy = policy
+	}
+}
+
+// WithRateLimiter delays requests, retries included, to keep them within the
+// rate allowed by limiter.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(client *Form3Client) {
+		client.RateLimiter = limiter
+	}
+}
+
+// WithValidation controls whether Create and Update validate objects before
+// sending them. Validation is enabled by default.
+func WithValidation(enabled bool) Option {
+	return func(client *Form3Client) {
+		client.SkipValidation = !enabled
+	}
+}
+
+// WithHTTPClient uses the given client instead of DefaultHTTPClient. Its
+// transport is still wrapped by any configured middleware.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Form3Client) {
+		client.HTTPClient = httpClient
+	}
+}
+
+// WithTransport replaces the transport of the underlying http.Client.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(client *Form3Client) {
+		client.Transport = transport
+	}
+}
+
+// WithMiddleware wraps the transport with the given middleware. The first
+// middleware registered is the first one to see a request.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(client *Form3Client) {
+		client.Middlewares = append(client.Middlewares, middlewares...)
+	}
+}
+
+// WithCircuitBreaker fails requests fast with ErrCircuitOpen once upstream
+// is failing, as decided by breaker.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return WithMiddleware(breaker.Transport)
+}
+
+// WithSigningKey signs every request with the given RSA key, following the
+// HTTP Signatures draft used by the Form3 API.
+func WithSigningKey(keyID string, privateKey *rsa.PrivateKey) Option {
+	return WithMiddleware(auth.NewSigner(keyID, privateKey).Transport)
+}
+
+// WithTokenSource authorizes every request with a bearer token from ts.
+func WithTokenSource(ts auth.TokenSource) Option {
+	return WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return auth.NewTokenTransport(ts, next)
+	})
+}
+
 func defaultOpts() []Option {
 	return []Option{
 		WithBaseURL("localhost:8080"),
@@ -1,13 +1,40 @@
 package pkg
 
 import (
+	"os"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fakeapi"
 )
 
+// testHost points to accountapi when TEST_API_HOST is set, otherwise to an
+// in-memory fake seeded with the fixtures.
+var testHost = os.Getenv("TEST_API_HOST")
+
+var fakeServer *fakeapi.Server
+
 func TestClient(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Client Suite")
 }
+
+var _ = BeforeSuite(func() {
+	if testHost != "" {
+		return
+	}
+
+	fakeServer = fakeapi.NewServer()
+	Expect(fakeServer.Seed(loadFixtures(api.Account{})...)).To(Succeed())
+
+	testHost = fakeServer.URL
+})
+
+var _ = AfterSuite(func() {
+	if fakeServer != nil {
+		fakeServer.Close()
+	}
+})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -29,6 +30,23 @@ func pseudoUUID() (string, error) {
 	return strings.ToLower(fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])), nil
 }
 
+// newGBAccount returns an account passing client-side validation.
+func newGBAccount(id string, version int) *api.Account {
+	account := api.NewAccount(id, version)
+	account.Type = "accounts"
+	account.OrganisationID = "721763e9-b2e2-4ebb-8de9-b440e3cf23a6"
+	account.Attributes = api.AccountAttributes{
+		Country:               "GB",
+		BaseCurrency:          "GBP",
+		BankID:                "400300",
+		BankIDCode:            "GBDSC",
+		BIC:                   "NWBKGB22",
+		AccountClassification: api.AccountClassificationPersonal,
+	}
+
+	return account
+}
+
 func loadFixture(path string, result *api.DataObject) error {
 	jsonFile, err := os.Open(path)
 	if err != nil {
@@ -41,6 +59,19 @@ func loadFixture(path string, result *api.DataObject) error {
 	return json.Unmarshal(byteValue, result)
 }
 
+// withoutTimestamps checks upstream set the timestamps of account, which
+// fixtures can't know, and clears them.
+func withoutTimestamps(account *api.Account) *api.Account {
+	Expect(account.CreatedOn).NotTo(BeNil())
+	Expect(account.ModifiedOn).NotTo(BeNil())
+
+	account.CreatedOn, account.ModifiedOn = nil, nil
+
+	return account
+}
+
+// loadFixtures loads the fixtures of the kind while the specs are built, so
+// it panics instead of failing a spec.
 func loadFixtures(kind api.Object) []api.Object {
 	var files []string
 
@@ -50,6 +81,10 @@ func loadFixtures(kind api.Object) []api.Object {
 	}
 
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
 		if strings.Contains(path, fmt.Sprintf("_%s_", api.Schema.TypeName(kind))) {
 			files = append(files, path)
 		}
@@ -64,28 +99,26 @@ func loadFixtures(kind api.Object) []api.Object {
 	for _, file := range files {
 		obj, err := api.Schema.NewDataObj(api.Schema.TypeName(kind))
 		if err != nil {
-			// TODO: log error
-			fmt.Println("err:", err)
+			panic(err)
 		}
 
-		if err := loadFixture(file, obj); err == nil {
-			objs = append(objs, obj.Data)
-		} else {
-			// TODO: log error
-			fmt.Println("err:", err)
+		if err := loadFixture(file, obj); err != nil {
+			panic(fmt.Errorf("%s: %w", file, err))
 		}
+
+		objs = append(objs, obj.Data)
 	}
 
 	return objs
 }
 
 var _ = Describe("Form3Client", func() {
-	host := os.Getenv("TEST_API_HOST")
-	if host == "" {
-		host = "http://localhost:8080"
-	}
+	var form3Client Client
+
+	BeforeEach(func() {
+		form3Client = NewClient(WithBaseURL(testHost))
+	})
 
-	form3Client := NewClient(WithBaseURL(host))
 	expectedAccounts := loadFixtures(api.Account{})
 
 	var entries []TableEntry
@@ -101,7 +134,7 @@ var _ = Describe("Form3Client", func() {
 			err := form3Client.Fetch(context.TODO(), account)
 			Expect(err).ShouldNot(HaveOccurred())
 
-			Expect(account).To(BeEquivalentTo(expectedAccount))
+			Expect(withoutTimestamps(account)).To(BeEquivalentTo(expectedAccount))
 		}, entries...)
 
 	Describe("fetch fails", func() {
@@ -111,7 +144,8 @@ var _ = Describe("Form3Client", func() {
 			err := form3Client.Fetch(context.TODO(), account)
 			Expect(err).Should(HaveOccurred())
 
-			Expect(err).To(BeEquivalentTo(fmt.Errorf(RespErrors[http.StatusNotFound],
+			Expect(IsNotFound(err)).To(BeTrue())
+			Expect(err).To(MatchError(fmt.Sprintf(RespErrors[http.StatusNotFound],
 				"record 20dba636-7fac-4747-b27a-327ca12b9b27 does not exist")))
 		})
 
@@ -121,7 +155,8 @@ var _ = Describe("Form3Client", func() {
 			err := form3Client.Fetch(context.TODO(), account)
 			Expect(err).Should(HaveOccurred())
 
-			Expect(err).To(BeEquivalentTo(fmt.Errorf(RespErrors[http.StatusBadRequest],
+			Expect(IsBadRequest(err)).To(BeTrue())
+			Expect(err).To(MatchError(fmt.Sprintf(RespErrors[http.StatusBadRequest],
 				"id is not a valid uuid")))
 		})
 
@@ -150,7 +185,7 @@ var _ = Describe("Form3Client", func() {
 			})
 
 			for i := range expectedAccounts {
-				Expect(&accounts.Items[i]).To(BeEquivalentTo((expectedAccounts[i]).(*api.Account)))
+				Expect(withoutTimestamps(&accounts.Items[i])).To(BeEquivalentTo((expectedAccounts[i]).(*api.Account)))
 			}
 		})
 		It("should return an error if the container is not valid", func() {
@@ -166,9 +201,7 @@ var _ = Describe("Form3Client", func() {
 			accounts := &api.AccountList{}
 
 			options := &ListOptions{
-				Filter: &ListFilter{
-					BankID: "400305",
-				},
+				Filter:     NewFilter().Where("bank_id", "400305"),
 				PageNumber: 1,
 				PageSize:   1,
 			}
@@ -202,7 +235,8 @@ var _ = Describe("Form3Client", func() {
 			err = form3Client.Fetch(context.TODO(), account)
 			Expect(err).Should(HaveOccurred())
 
-			Expect(err).To(BeEquivalentTo(fmt.Errorf(RespErrors[http.StatusNotFound],
+			Expect(err).To(MatchError(ErrNotFound))
+			Expect(err).To(MatchError(fmt.Sprintf(RespErrors[http.StatusNotFound],
 				fmt.Sprintf("record %s does not exist", account.GetID()))))
 		})
 
@@ -219,7 +253,8 @@ var _ = Describe("Form3Client", func() {
 			err := form3Client.Delete(context.TODO(), account)
 			Expect(err).Should(HaveOccurred())
 
-			Expect(err).To(BeEquivalentTo(fmt.Errorf(RespErrors[http.StatusBadRequest],
+			Expect(IsBadRequest(err)).To(BeTrue())
+			Expect(err).To(MatchError(fmt.Sprintf(RespErrors[http.StatusBadRequest],
 				"id is not a valid uuid")))
 		})
 
@@ -248,7 +283,8 @@ var _ = Describe("Form3Client", func() {
 			err := form3Client.Delete(context.TODO(), account)
 			Expect(err).Should(HaveOccurred())
 
-			Expect(err).To(BeEquivalentTo(fmt.Errorf(RespErrors[http.StatusNotFound], "invalid version")))
+			Expect(IsNotFound(err)).To(BeTrue())
+			Expect(err).To(MatchError(fmt.Sprintf(RespErrors[http.StatusNotFound], "invalid version")))
 		})
 	})
 
@@ -302,11 +338,35 @@ var _ = Describe("Form3Client", func() {
 				Attributes: api.AccountAttributes{},
 			}
 
-			err = form3Client.Create(context.TODO(), account)
+			unvalidated := NewClient(WithBaseURL(testHost), WithValidation(false))
+
+			err = unvalidated.Create(context.TODO(), account)
 			Expect(err).Should(HaveOccurred())
 
+			Expect(IsBadRequest(err)).To(BeTrue())
 			Expect(err.Error()).To(ContainSubstring("invalid request"))
 			Expect(err.Error()).To(ContainSubstring("account_classification in body should be one of [Personal Business]"))
 		})
+
+		It("should validate accounts before sending them", func() {
+			uuid, err := pseudoUUID()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			account := newGBAccount(uuid, 0)
+			account.Attributes.BankID = "4003"
+			account.Attributes.AccountClassification = ""
+
+			err = form3Client.Create(context.TODO(), account)
+			Expect(err).Should(HaveOccurred())
+
+			var validationErrs api.ValidationErrors
+			Expect(errors.As(err, &validationErrs)).To(BeTrue())
+			Expect(validationErrs.Fields()).To(Equal([]string{
+				"attributes.account_classification",
+				"attributes.bank_id",
+			}))
+
+			Expect(IsNotFound(form3Client.Fetch(context.TODO(), api.NewAccount(uuid, 0)))).To(BeTrue())
+		})
 	})
 })
@@ -0,0 +1,159 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var RespErrors = map[int]string{
+	http.StatusBadRequest:          "invalid request: %s",
+	http.StatusUnauthorized:        "not authorized: %s",
+	http.StatusForbidden:           "forbidden: %s",
+	http.StatusNotFound:            "not found: %s",
+	http.StatusMethodNotAllowed:    "method not allowed: %s",
+	http.StatusNotAcceptable:       "not acceptable: %s",
+	http.StatusConflict:            "conflict: %s",
+	http.StatusTooManyRequests:     "too many requests: %s",
+	http.StatusInternalServerError: "server error %s",
+	http.StatusBadGateway:          "bad gateway %s",
+	http.StatusServiceUnavailable:  "service unavailable %s",
+	http.StatusGatewayTimeout:      "gateway timeout %s",
+}
+
+var ErrInvalidObjectType = errors.New("invalid object type")
+
+// Sentinel errors matched by errors.Is against any *APIError with the same
+// status code.
+var (
+	ErrBadRequest         = &APIError{StatusCode: http.StatusBadRequest}
+	ErrUnauthorized       = &APIError{StatusCode: http.StatusUnauthorized}
+	ErrForbidden          = &APIError{StatusCode: http.StatusForbidden}
+	ErrNotFound           = &APIError{StatusCode: http.StatusNotFound}
+	ErrConflict           = &APIError{StatusCode: http.StatusConflict}
+	ErrTooManyRequests    = &APIError{StatusCode: http.StatusTooManyRequests}
+	ErrServiceUnavailable = &APIError{StatusCode: http.StatusServiceUnavailable}
+)
+
+const MissingOrInvalidArgumentFmt = "missing or invalid argument: %s"
+const DefaultResponseErrorFmt = "error: %s"
+
+// UnknownStatusErrorFmt formats errors for status codes missing from
+// RespErrors, with the code and the message.
+const UnknownStatusErrorFmt = "error %d: %s"
+
+type errorResponse struct {
+	ErrorMessage string `json:"error_message"`
+	ErrorCode    string `json:"error_code"`
+}
+
+// APIError is returned for every non 2xx response received from upstream.
+type APIError struct {
+	StatusCode   int
+	ErrorMessage string
+	ErrorCode    string
+
+	Method string
+	URL    string
+
+	Body   []byte
+	Header http.Header
+
+	// Attempts is how many times the request was sent, more than 1 if it
+	// was retried.
+	Attempts int
+	// Err is why the body of the response couldn't be read, if it couldn't.
+	Err error
+}
+
+func (e *APIError) Error() string {
+	respError, exists := RespErrors[e.StatusCode]
+	if !exists {
+		if e.ErrorMessage != "" {
+			return fmt.Sprintf(UnknownStatusErrorFmt, e.StatusCode, e.ErrorMessage)
+		}
+
+		return fmt.Sprintf(UnknownStatusErrorFmt, e.StatusCode, e.Body)
+	}
+
+	return fmt.Sprintf(respError, e.ErrorMessage)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *APIError with the same status code, which
+// allows errors.Is(err, ErrNotFound).
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+
+	return e.StatusCode == t.StatusCode
+}
+
+// VersionConflictError is returned by Update when the version sent doesn't
+// match the one stored upstream.
+type VersionConflictError struct {
+	Version int
+	Err     *APIError
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("version %d is out of date: %s", e.Version, e.Err)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return e.Err
+}
+
+func IsVersionConflict(err error) bool {
+	var conflictErr *VersionConflictError
+
+	return errors.As(err, &conflictErr)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == statusCode
+	}
+
+	return false
+}
+
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
+
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func IsTooManyRequests(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsServerError reports whether err was caused by a 5xx response.
+func IsServerError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	return false
+}
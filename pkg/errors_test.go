@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("APIError", func() {
+	var (
+		server     *httptest.Server
+		statusCode int
+		body       string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", "42")
+			w.WriteHeader(statusCode)
+			_, _ = w.Write([]byte(body))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should carry the upstream response details", func() {
+		statusCode = http.StatusConflict
+		body = `{"error_message": "invalid version", "error_code": "c4b6ff13-fb43-4f6a-8fcf-6c1dbc5a0c1d"}`
+
+		form3Client := NewClient(WithBaseURL(server.URL))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 3)
+
+		err := form3Client.Delete(context.TODO(), account)
+		Expect(err).Should(HaveOccurred())
+
+		var apiErr *APIError
+		Expect(errors.As(err, &apiErr)).To(BeTrue())
+
+		Expect(apiErr.StatusCode).To(Equal(http.StatusConflict))
+		Expect(apiErr.ErrorMessage).To(Equal("invalid version"))
+		Expect(apiErr.ErrorCode).To(Equal("c4b6ff13-fb43-4f6a-8fcf-6c1dbc5a0c1d"))
+		Expect(apiErr.Method).To(Equal(http.MethodDelete))
+		Expect(apiErr.URL).To(HaveSuffix("/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc?version=3"))
+		Expect(apiErr.Body).To(MatchJSON(body))
+		Expect(apiErr.Header.Get("X-Request-Id")).To(Equal("42"))
+
+		Expect(IsConflict(err)).To(BeTrue())
+		Expect(IsNotFound(err)).To(BeFalse())
+		Expect(errors.Is(err, ErrConflict)).To(BeTrue())
+		Expect(err).To(MatchError("conflict: invalid version"))
+	})
+
+	It("should cover status codes without a known message", func() {
+		statusCode = http.StatusTeapot
+		body = "short and stout"
+
+		form3Client := NewClient(WithBaseURL(server.URL))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Fetch(context.TODO(), account)
+		Expect(err).To(MatchError("error 418: short and stout"))
+		Expect(errors.Is(err, &APIError{StatusCode: http.StatusTeapot})).To(BeTrue())
+		Expect(IsServerError(err)).To(BeFalse())
+	})
+
+	It("should keep the status when the body can't be read", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_message"`))
+		})
+
+		form3Client := NewClient(WithBaseURL(server.URL))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Fetch(context.TODO(), account)
+		Expect(IsNotFound(err)).To(BeTrue())
+		Expect(errors.Is(err, io.ErrUnexpectedEOF)).To(BeTrue())
+		Expect(err).To(MatchError("not found: couldn't read response from server: unexpected EOF"))
+	})
+
+	It("should be reachable through retry errors", func() {
+		statusCode = http.StatusServiceUnavailable
+		body = `{"error_message": "down for maintenance"}`
+
+		policy := DefaultRetryPolicy()
+		policy.MaxAttempts = 1
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Fetch(context.TODO(), account)
+		Expect(errors.Is(err, ErrServiceUnavailable)).To(BeTrue())
+		Expect(IsServerError(err)).To(BeTrue())
+	})
+})
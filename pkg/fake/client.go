@@ -0,0 +1,361 @@
+// Package fake provides an in-memory implementation of pkg.Client, modelled
+// after client-go's fake clientset, for unit testing code built on top of it.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+)
+
+const (
+	VerbFetch  = "fetch"
+	VerbList   = "list"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+
+	// Any matches every verb or kind when registering reactors.
+	Any = "*"
+)
+
+// Action records a call made against the fake client.
+type Action struct {
+	Verb   string
+	Kind   string
+	ID     string
+	Object api.Object
+}
+
+// ReactionFunc is called for matching actions. If handled is true, err is
+// returned to the caller and the tracker isn't touched.
+type ReactionFunc func(action Action) (handled bool, err error)
+
+type reactor struct {
+	verb     string
+	kind     string
+	reaction ReactionFunc
+}
+
+func (r *reactor) matches(action Action) bool {
+	return (r.verb == Any || r.verb == action.Verb) && (r.kind == Any || r.kind == action.Kind)
+}
+
+// Client implements pkg.Client against a Tracker.
+type Client struct {
+	tracker *Tracker
+
+	mu       sync.Mutex
+	reactors []*reactor
+	actions  []Action
+}
+
+var _ pkg.Client = &Client{}
+
+// NewClient returns a fake client whose tracker already holds objects.
+func NewClient(objects ...api.Object) *Client {
+	tracker := NewTracker()
+
+	for _, obj := range objects {
+		if err := tracker.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+
+	return &Client{tracker: tracker}
+}
+
+func (c *Client) Tracker() *Tracker {
+	return c.tracker
+}
+
+// PrependReactor registers a reaction for the given verb and kind, e.g.
+// ("create", "api.Account"). Use Any to match everything.
+func (c *Client) PrependReactor(verb, kind string, reaction ReactionFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reactors = append([]*reactor{{verb: verb, kind: kind, reaction: reaction}}, c.reactors...)
+}
+
+// Actions returns the actions invoked so far, in order.
+func (c *Client) Actions() []Action {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	actions := make([]Action, len(c.actions))
+	copy(actions, c.actions)
+
+	return actions
+}
+
+func (c *Client) ClearActions() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.actions = nil
+}
+
+// invoke records the action and runs the matching reactors.
+func (c *Client) invoke(verb string, obj api.Object) (bool, error) {
+	action := Action{
+		Verb:   verb,
+		Kind:   api.Schema.TypeName(obj),
+		ID:     obj.GetID(),
+		Object: obj,
+	}
+
+	c.mu.Lock()
+	c.actions = append(c.actions, action)
+	reactors := make([]*reactor, len(c.reactors))
+	copy(reactors, c.reactors)
+	c.mu.Unlock()
+
+	for _, r := range reactors {
+		if !r.matches(action) {
+			continue
+		}
+
+		if handled, err := r.reaction(action); handled {
+			return true, err
+		}
+	}
+
+	return false, nil
+}
+
+func (c *Client) Fetch(ctx context.Context, obj api.Object) error {
+	if handled, err := c.invoke(VerbFetch, obj); handled {
+		return err
+	}
+
+	if obj.GetID() == "" {
+		return fmt.Errorf(pkg.MissingOrInvalidArgumentFmt, "uuid")
+	}
+
+	stored, err := c.tracker.Get(obj)
+	if err != nil {
+		return err
+	}
+
+	return copyInto(obj, stored)
+}
+
+func (c *Client) List(ctx context.Context, obj api.Object, listOptions *pkg.ListOptions) error {
+	pager := c.Pager(obj, listOptions)
+
+	v, err := api.EnforcePtr(obj)
+	if err != nil {
+		return err
+	}
+
+	items := v.FieldByName("Items")
+	if !items.IsValid() {
+		return pkg.ErrInvalidObjectType
+	}
+
+	results := reflect.MakeSlice(items.Type(), 0, 1)
+
+	for pager.Next(ctx) {
+		results = reflect.AppendSlice(results, items)
+	}
+
+	if err := pager.Err(); err != nil {
+		return err
+	}
+
+	items.Set(results)
+
+	return nil
+}
+
+// Pager pages through the tracked objects. Cursors are the page numbers.
+func (c *Client) Pager(obj api.Object, listOptions *pkg.ListOptions) *pkg.Pager {
+	cursor, pageSize := "0", 0
+
+	if listOptions != nil {
+		cursor, pageSize = strconv.Itoa(listOptions.PageNumber), listOptions.PageSize
+		if listOptions.Cursor != "" {
+			cursor = listOptions.Cursor
+		}
+	}
+
+	return pkg.NewPager(obj, cursor, func(ctx context.Context, list api.Object, cursor string) (string, error) {
+		// Filters aren't applied, but unknown ones are rejected like
+		// pkg.Form3Client does.
+		if listOptions != nil {
+			if _, err := listOptions.Values(list); err != nil {
+				return "", err
+			}
+		}
+
+		return c.listPage(list, cursor, pageSize)
+	})
+}
+
+func (c *Client) ListIter(obj api.Object, listOptions *pkg.ListOptions) *pkg.ListIter {
+	return pkg.NewListIter(c.Pager(obj, listOptions))
+}
+
+func (c *Client) listPage(list api.Object, cursor string, pageSize int) (string, error) {
+	if handled, err := c.invoke(VerbList, list); handled {
+		return "", err
+	}
+
+	page, err := strconv.Atoi(cursor)
+	if err != nil {
+		return "", fmt.Errorf(pkg.MissingOrInvalidArgumentFmt, "cursor")
+	}
+
+	v, err := api.EnforcePtr(list)
+	if err != nil {
+		return "", err
+	}
+
+	items := v.FieldByName("Items")
+	if !items.IsValid() {
+		return "", pkg.ErrInvalidObjectType
+	}
+
+	objs, err := c.tracker.List(items.Type().Elem().String())
+	if err != nil {
+		return "", err
+	}
+
+	if parent, ok := list.(api.Child); ok {
+		objs = childrenOf(parent.GetParentID(), objs)
+	}
+
+	start, end := 0, len(objs)
+	if pageSize > 0 {
+		start, end = page*pageSize, (page+1)*pageSize
+	}
+
+	// Pages past the last object are empty, as upstream.
+	if start > len(objs) {
+		start = len(objs)
+	}
+
+	if end > len(objs) {
+		end = len(objs)
+	}
+
+	data := reflect.MakeSlice(items.Type(), 0, end-start)
+	for i := start; i < end; i++ {
+		data = reflect.Append(data, reflect.ValueOf(objs[i]).Elem())
+	}
+
+	items.Set(data)
+
+	if end >= len(objs) {
+		return "", nil
+	}
+
+	return strconv.Itoa(page + 1), nil
+}
+
+// childrenOf keeps the objects nested under the parent with the given ID.
+func childrenOf(parentID string, objs []api.Object) []api.Object {
+	children := make([]api.Object, 0, len(objs))
+
+	for _, obj := range objs {
+		if child, ok := obj.(api.Child); ok && child.GetParentID() == parentID {
+			children = append(children, obj)
+		}
+	}
+
+	return children
+}
+
+func (c *Client) Create(ctx context.Context, obj api.Object) error {
+	if handled, err := c.invoke(VerbCreate, obj); handled {
+		return err
+	}
+
+	created, err := c.tracker.Create(obj)
+	if err != nil {
+		return err
+	}
+
+	if _, err := api.EnforcePtr(obj); err != nil {
+		return nil
+	}
+
+	return copyInto(obj, created)
+}
+
+func (c *Client) Update(ctx context.Context, obj api.Object) error {
+	if handled, err := c.invoke(VerbUpdate, obj); handled {
+		return err
+	}
+
+	if _, err := api.EnforcePtr(obj); err != nil {
+		return err
+	}
+
+	if obj.GetID() == "" {
+		return fmt.Errorf(pkg.MissingOrInvalidArgumentFmt, "ID")
+	}
+
+	updated, err := c.tracker.Update(obj)
+	if err != nil {
+		return err
+	}
+
+	return copyInto(obj, updated)
+}
+
+func (c *Client) Delete(ctx context.Context, obj api.Object) error {
+	if handled, err := c.invoke(VerbDelete, obj); handled {
+		return err
+	}
+
+	if obj.GetID() == "" {
+		return fmt.Errorf(pkg.MissingOrInvalidArgumentFmt, "ID")
+	}
+
+	if obj.GetVersion() < 0 {
+		return fmt.Errorf(pkg.MissingOrInvalidArgumentFmt, "Version")
+	}
+
+	return c.tracker.Delete(obj)
+}
+
+// Apply converges the tracker towards the desired objects through the
+// other verbs, which record their actions.
+func (c *Client) Apply(ctx context.Context, desired []api.Object, opts *pkg.ApplyOptions) ([]*pkg.ApplyResult, error) {
+	return pkg.Apply(ctx, c, desired, opts)
+}
+
+// BatchCreate creates objs concurrently through Create, which records the
+// actions.
+func (c *Client) BatchCreate(ctx context.Context, objs []api.Object, opts *pkg.BatchOptions) ([]*pkg.BatchResult, error) {
+	return pkg.BatchCreate(ctx, c, objs, opts)
+}
+
+// BatchDelete deletes objs concurrently through Delete.
+func (c *Client) BatchDelete(ctx context.Context, objs []api.Object, opts *pkg.BatchOptions) ([]*pkg.BatchResult, error) {
+	return pkg.BatchDelete(ctx, c, objs, opts)
+}
+
+// BatchFetch fetches objs concurrently through Fetch.
+func (c *Client) BatchFetch(ctx context.Context, objs []api.Object, opts *pkg.BatchOptions) ([]*pkg.BatchResult, error) {
+	return pkg.BatchFetch(ctx, c, objs, opts)
+}
+
+func (c *Client) Accounts() *pkg.AccountsClient {
+	return pkg.NewTypedClient[api.Account, api.AccountList](c)
+}
+
+func (c *Client) Payments() *pkg.PaymentsClient {
+	return pkg.NewTypedClient[api.Payment, api.PaymentList](c)
+}
+
+func (c *Client) Subscriptions() *pkg.SubscriptionsClient {
+	return pkg.NewTypedClient[api.Subscription, api.SubscriptionList](c)
+}
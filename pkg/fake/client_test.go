@@ -0,0 +1,209 @@
+package fake
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		client   *Client
+		accounts []*api.Account
+	)
+
+	BeforeEach(func() {
+		accounts = []*api.Account{
+			api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0),
+			api.NewAccount("bd27e265-9605-4b4b-a0e5-3003ea9cc4dc", 1),
+			api.NewAccount("cd27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0),
+		}
+		accounts[1].Attributes.BankID = "400300"
+
+		client = NewClient(accounts[0], accounts[1], *accounts[2])
+	})
+
+	It("should fetch tracked objects", func() {
+		account := api.NewAccount(accounts[1].ID, 0)
+
+		Expect(client.Fetch(context.TODO(), account)).To(Succeed())
+		Expect(account).To(Equal(accounts[1]))
+	})
+
+	It("should return not found for missing objects", func() {
+		err := client.Fetch(context.TODO(), api.NewAccount("20dba636-7fac-4747-b27a-327ca12b9b27", 0))
+
+		Expect(pkg.IsNotFound(err)).To(BeTrue())
+		Expect(err).To(MatchError("not found: record 20dba636-7fac-4747-b27a-327ca12b9b27 does not exist"))
+	})
+
+	It("should list and page through objects", func() {
+		list := &api.AccountList{}
+
+		Expect(client.List(context.TODO(), list, nil)).To(Succeed())
+		Expect(list.Items).To(HaveLen(3))
+		Expect(list.Items[2].ID).To(Equal(accounts[2].ID))
+
+		pager := client.Pager(list, &pkg.ListOptions{PageSize: 2})
+		Expect(pager.Next(context.TODO())).To(BeTrue())
+		Expect(list.Items).To(HaveLen(2))
+		Expect(pager.Next(context.TODO())).To(BeTrue())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(pager.Next(context.TODO())).To(BeFalse())
+		Expect(pager.Err()).ShouldNot(HaveOccurred())
+	})
+
+	It("should return an empty page past the last object", func() {
+		list := &api.AccountList{}
+
+		Expect(client.List(context.TODO(), list, &pkg.ListOptions{PageNumber: 5, PageSize: 2})).To(Succeed())
+		Expect(list.Items).To(BeEmpty())
+	})
+
+	It("should only list the children of the given parent", func() {
+		paymentID := "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
+
+		Expect(client.Create(context.TODO(), api.NewPaymentReturn(paymentID, accounts[0].ID, 0))).To(Succeed())
+		Expect(client.Create(context.TODO(), api.NewPaymentReturn(accounts[2].ID, accounts[1].ID, 0))).To(Succeed())
+
+		list := &api.PaymentReturnList{PaymentID: paymentID}
+		Expect(client.List(context.TODO(), list, nil)).To(Succeed())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].ID).To(Equal(accounts[0].ID))
+	})
+
+	It("should keep children with the same ID under different parents apart", func() {
+		first, second := accounts[0].ID, accounts[1].ID
+		returnID := "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
+
+		Expect(client.Create(context.TODO(), api.NewPaymentReturn(first, returnID, 0))).To(Succeed())
+		Expect(client.Create(context.TODO(), api.NewPaymentReturn(second, returnID, 0))).To(Succeed())
+
+		Expect(client.Delete(context.TODO(), api.NewPaymentReturn(first, returnID, 0))).To(Succeed())
+		Expect(pkg.IsNotFound(client.Fetch(context.TODO(), api.NewPaymentReturn(first, returnID, 0)))).To(BeTrue())
+
+		kept := api.NewPaymentReturn(second, returnID, 0)
+		Expect(client.Fetch(context.TODO(), kept)).To(Succeed())
+		Expect(kept.GetParentID()).To(Equal(second))
+	})
+
+	It("should reject lists without items", func() {
+		Expect(client.List(context.TODO(), &api.Account{}, nil)).To(Equal(pkg.ErrInvalidObjectType))
+	})
+
+	It("should create objects with version 0 and reject duplicates", func() {
+		account := api.NewAccount("20dba636-7fac-4747-b27a-327ca12b9b27", 3)
+
+		Expect(client.Create(context.TODO(), account)).To(Succeed())
+		Expect(account.Version).To(Equal(0))
+
+		Expect(pkg.IsConflict(client.Create(context.TODO(), account))).To(BeTrue())
+	})
+
+	It("should enforce versions on update", func() {
+		account := api.NewAccount(accounts[1].ID, 1)
+		account.Attributes.BankID = "400302"
+
+		Expect(client.Update(context.TODO(), account)).To(Succeed())
+		Expect(account.Version).To(Equal(2))
+
+		stale := api.NewAccount(accounts[1].ID, 1)
+		Expect(pkg.IsVersionConflict(client.Update(context.TODO(), stale))).To(BeTrue())
+
+		fetched, err := client.Accounts().Get(context.TODO(), accounts[1].ID)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fetched.Attributes.BankID).To(Equal("400302"))
+	})
+
+	It("should enforce versions on delete", func() {
+		err := client.Delete(context.TODO(), api.NewAccount(accounts[1].ID, 0))
+		Expect(err).To(MatchError("not found: invalid version"))
+
+		Expect(client.Delete(context.TODO(), api.NewAccount(accounts[1].ID, 1))).To(Succeed())
+		Expect(pkg.IsNotFound(client.Fetch(context.TODO(), api.NewAccount(accounts[1].ID, 0)))).To(BeTrue())
+
+		Expect(client.Delete(context.TODO(), api.NewAccount(accounts[1].ID, 1))).To(Succeed())
+	})
+
+	It("should inject errors through reactors", func() {
+		boom := errors.New("boom")
+
+		client.PrependReactor(VerbCreate, "api.Account", func(action Action) (bool, error) {
+			return true, boom
+		})
+
+		Expect(client.Create(context.TODO(), api.NewAccount("20dba636-7fac-4747-b27a-327ca12b9b27", 0))).To(Equal(boom))
+		Expect(client.Fetch(context.TODO(), api.NewAccount(accounts[0].ID, 0))).To(Succeed())
+	})
+
+	It("should fall through reactors that don't handle the action", func() {
+		var seen []string
+
+		client.PrependReactor(Any, Any, func(action Action) (bool, error) {
+			seen = append(seen, action.Verb)
+			return false, nil
+		})
+
+		Expect(client.Fetch(context.TODO(), api.NewAccount(accounts[0].ID, 0))).To(Succeed())
+		Expect(seen).To(Equal([]string{VerbFetch}))
+	})
+
+	It("should record actions", func() {
+		_ = client.Fetch(context.TODO(), api.NewAccount(accounts[0].ID, 0))
+		_ = client.Delete(context.TODO(), api.NewAccount(accounts[2].ID, 0))
+		_ = client.List(context.TODO(), &api.AccountList{}, nil)
+
+		actions := client.Actions()
+		Expect(actions).To(HaveLen(3))
+
+		Expect(actions[0].Verb).To(Equal(VerbFetch))
+		Expect(actions[0].Kind).To(Equal("api.Account"))
+		Expect(actions[0].ID).To(Equal(accounts[0].ID))
+		Expect(actions[1].Verb).To(Equal(VerbDelete))
+		Expect(actions[2].Verb).To(Equal(VerbList))
+		Expect(actions[2].Kind).To(Equal("api.AccountList"))
+
+		client.ClearActions()
+		Expect(client.Actions()).To(BeEmpty())
+	})
+
+	It("should apply through the tracker", func() {
+		desired := api.NewAccount(accounts[1].ID, 0)
+		desired.Attributes.BankID = "400302"
+
+		results, err := client.Apply(context.TODO(), []api.Object{desired}, &pkg.ApplyOptions{Prune: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].Action).To(Equal(pkg.ApplyUpdate))
+
+		var verbs []string
+		for _, action := range client.Actions() {
+			verbs = append(verbs, action.Verb)
+		}
+
+		Expect(verbs).To(Equal([]string{VerbFetch, VerbUpdate, VerbList, VerbDelete, VerbDelete}))
+
+		list := &api.AccountList{}
+		Expect(client.List(context.TODO(), list, nil)).To(Succeed())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Attributes.BankID).To(Equal("400302"))
+	})
+
+	It("should run batches through the tracker", func() {
+		missing := api.NewAccount("20dba636-7fac-4747-b27a-327ca12b9b27", 0)
+
+		results, err := client.BatchFetch(context.TODO(), []api.Object{api.NewAccount(accounts[1].ID, 0), missing}, nil)
+		Expect(err.(*pkg.BatchError).IDs()).To(Equal([]string{missing.ID}))
+		Expect(results[0].Object).To(Equal(accounts[1]))
+		Expect(errors.Is(results[1].Err, pkg.ErrNotFound)).To(BeTrue())
+
+		_, err = client.BatchDelete(context.TODO(), []api.Object{accounts[0], accounts[1]}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.Actions()).To(HaveLen(4))
+	})
+})
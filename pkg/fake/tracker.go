@@ -0,0 +1,231 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+)
+
+// Tracker keeps objects in memory, keyed by their Scheme type name, parent
+// and ID, and enforces the same version semantics as upstream.
+type Tracker struct {
+	mu      sync.RWMutex
+	objects map[string]map[string]api.Object
+	order   map[string][]string
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		objects: map[string]map[string]api.Object{},
+		order:   map[string][]string{},
+	}
+}
+
+// key identifies obj among the objects of its kind. Children are keyed by
+// their parent too, since their IDs only need to be unique under it.
+func key(obj api.Object) string {
+	if child, ok := obj.(api.Child); ok {
+		return child.GetParentID() + "/" + obj.GetID()
+	}
+
+	return obj.GetID()
+}
+
+func notFound(id string) error {
+	return &pkg.APIError{
+		StatusCode:   http.StatusNotFound,
+		ErrorMessage: fmt.Sprintf("record %s does not exist", id),
+	}
+}
+
+// deepCopy returns a pointer to a copy of obj, which can be either a value or
+// a pointer.
+func deepCopy(obj api.Object) (api.Object, error) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := api.Schema.TypeName(obj)
+
+	result, err := api.Schema.NewObj(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// copyInto overwrites the object obj points to with src.
+func copyInto(obj, src api.Object) error {
+	dest, err := api.EnforcePtr(obj)
+	if err != nil {
+		return err
+	}
+
+	dest.Set(reflect.ValueOf(src).Elem())
+
+	return nil
+}
+
+// Add stores obj as is, replacing any object with the same ID and parent.
+func (t *Tracker) Add(obj api.Object) error {
+	stored, err := deepCopy(obj)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.add(api.Schema.TypeName(obj), stored)
+
+	return nil
+}
+
+func (t *Tracker) add(kind string, obj api.Object) {
+	if t.objects[kind] == nil {
+		t.objects[kind] = map[string]api.Object{}
+	}
+
+	if _, exists := t.objects[kind][key(obj)]; !exists {
+		t.order[kind] = append(t.order[kind], key(obj))
+	}
+
+	t.objects[kind][key(obj)] = obj
+}
+
+// Get returns a copy of the stored object with the kind, ID and parent, if
+// any, of obj.
+func (t *Tracker) Get(obj api.Object) (api.Object, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	stored, exists := t.objects[api.Schema.TypeName(obj)][key(obj)]
+	if !exists {
+		return nil, notFound(obj.GetID())
+	}
+
+	return deepCopy(stored)
+}
+
+// List returns copies of every object of the given kind, in insertion order.
+func (t *Tracker) List(kind string) ([]api.Object, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	objs := make([]api.Object, 0, len(t.order[kind]))
+
+	for _, k := range t.order[kind] {
+		obj, err := deepCopy(t.objects[kind][k])
+		if err != nil {
+			return nil, err
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// Create stores obj with version 0, failing if the ID is already taken under
+// the same parent.
+func (t *Tracker) Create(obj api.Object) (api.Object, error) {
+	kind := api.Schema.TypeName(obj)
+
+	stored, err := deepCopy(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if setter, ok := stored.(interface{ SetVersion(int) }); ok {
+		setter.SetVersion(0)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, exists := t.objects[kind][key(obj)]; exists {
+		return nil, &pkg.APIError{
+			StatusCode:   http.StatusConflict,
+			ErrorMessage: fmt.Sprintf("%s cannot be created as it violates a duplicate constraint", strings.TrimPrefix(kind, "api.")),
+		}
+	}
+
+	t.add(kind, stored)
+
+	return deepCopy(stored)
+}
+
+// Update replaces the stored object if obj carries the current version, and
+// bumps the version.
+func (t *Tracker) Update(obj api.Object) (api.Object, error) {
+	kind := api.Schema.TypeName(obj)
+
+	stored, err := deepCopy(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, exists := t.objects[kind][key(obj)]
+	if !exists {
+		return nil, notFound(obj.GetID())
+	}
+
+	if current.GetVersion() != obj.GetVersion() {
+		return nil, &pkg.VersionConflictError{
+			Version: obj.GetVersion(),
+			Err:     &pkg.APIError{StatusCode: http.StatusConflict, ErrorMessage: "invalid version"},
+		}
+	}
+
+	if setter, ok := stored.(interface{ SetVersion(int) }); ok {
+		setter.SetVersion(obj.GetVersion() + 1)
+	}
+
+	t.add(kind, stored)
+
+	return deepCopy(stored)
+}
+
+// Delete removes the object if obj carries the current version. Deleting a
+// missing object succeeds, as it does upstream.
+func (t *Tracker) Delete(obj api.Object) error {
+	kind := api.Schema.TypeName(obj)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, exists := t.objects[kind][key(obj)]
+	if !exists {
+		return nil
+	}
+
+	if current.GetVersion() != obj.GetVersion() {
+		return &pkg.APIError{StatusCode: http.StatusNotFound, ErrorMessage: "invalid version"}
+	}
+
+	delete(t.objects[kind], key(obj))
+
+	for i, k := range t.order[kind] {
+		if k == key(obj) {
+			t.order[kind] = append(t.order[kind][:i], t.order[kind][i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
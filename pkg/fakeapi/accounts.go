@@ -0,0 +1,55 @@
+package fakeapi
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type pattern struct {
+	field  string
+	regexp *regexp.Regexp
+}
+
+var accountPatterns = []pattern{
+	{"country", regexp.MustCompile(`^[A-Z]{2}$`)},
+	{"base_currency", regexp.MustCompile(`^[A-Z]{3}$`)},
+	{"bank_id", regexp.MustCompile(`^[A-Z0-9]{0,16}$`)},
+	{"bank_id_code", regexp.MustCompile(`^[A-Z]{0,16}$`)},
+	{"bic", regexp.MustCompile(`^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$`)},
+	{"iban", regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{0,64}$`)},
+	{"account_number", regexp.MustCompile(`^[A-Z0-9]{0,64}$`)},
+}
+
+var accountClassifications = map[string]bool{
+	"Personal": true,
+	"Business": true,
+}
+
+// validateAccount mirrors the checks accountapi runs on account attributes.
+func validateAccount(data record) []string {
+	attributes, ok := data["attributes"].(map[string]interface{})
+	if !ok {
+		return []string{"attributes in body is required"}
+	}
+
+	var failures []string
+
+	if country, _ := attributes["country"].(string); country == "" {
+		failures = append(failures, "country in body is required")
+	}
+
+	for _, p := range accountPatterns {
+		value, _ := attributes[p.field].(string)
+		if value != "" && !p.regexp.MatchString(value) {
+			failures = append(failures, fmt.Sprintf("%s in body should match '%s'", p.field, p.regexp))
+		}
+	}
+
+	if classification, exists := attributes["account_classification"]; exists {
+		if value, _ := classification.(string); !accountClassifications[value] {
+			failures = append(failures, "account_classification in body should be one of [Personal Business]")
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,51 @@
+package fakeapi
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var paymentPatterns = []pattern{
+	{"amount", regexp.MustCompile(`^[0-9]{0,20}(\.[0-9]{1,10})?$`)},
+	{"currency", regexp.MustCompile(`^[A-Z]{3}$`)},
+	{"processing_date", regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)},
+}
+
+var paymentSchemes = map[string]bool{
+	"FPS":    true,
+	"BACS":   true,
+	"CHAPS":  true,
+	"SEPACT": true,
+}
+
+// validatePayment mirrors the checks the payments API runs on payment
+// attributes.
+func validatePayment(data record) []string {
+	attributes, ok := data["attributes"].(map[string]interface{})
+	if !ok {
+		return []string{"attributes in body is required"}
+	}
+
+	var failures []string
+
+	for _, field := range []string{"amount", "currency"} {
+		if value, _ := attributes[field].(string); value == "" {
+			failures = append(failures, fmt.Sprintf("%s in body is required", field))
+		}
+	}
+
+	for _, p := range paymentPatterns {
+		value, _ := attributes[p.field].(string)
+		if value != "" && !p.regexp.MatchString(value) {
+			failures = append(failures, fmt.Sprintf("%s in body should match '%s'", p.field, p.regexp))
+		}
+	}
+
+	if scheme, exists := attributes["payment_scheme"]; exists {
+		if value, _ := scheme.(string); !paymentSchemes[value] {
+			failures = append(failures, "payment_scheme in body should be one of [FPS BACS CHAPS SEPACT]")
+		}
+	}
+
+	return failures
+}
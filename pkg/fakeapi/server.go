@@ -0,0 +1,530 @@
+// Package fakeapi provides an in-memory stand-in for the Form3 accounts,
+// payments and subscriptions APIs, so the client can be tested without
+// running accountapi, postgres and vault.
+package fakeapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+const (
+	Version         = "v1"
+	DefaultPageSize = 100
+	contentType     = "application/vnd.api+json"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[[:xdigit:]]{8}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{12}$`)
+
+type record = map[string]interface{}
+
+// ValidateFunc returns the validation failures of a record about to be
+// created, using the same messages as upstream.
+type ValidateFunc func(record) []string
+
+type collection struct {
+	recordType string
+	validate   ValidateFunc
+	records    map[string]record
+	order      []string
+
+	// nested is set for collections created under a parent record.
+	nested bool
+}
+
+// template describes collections nested under the records of a parent
+// collection, e.g. transaction/payments/%s/submissions.
+type template struct {
+	pattern    *regexp.Regexp
+	recordType string
+	validate   ValidateFunc
+}
+
+// name returns the singular, capitalised record type, e.g. Account.
+func (c *collection) name() string {
+	name := strings.ReplaceAll(strings.TrimSuffix(c.recordType, "s"), "_", " ")
+	if name == "" {
+		return name
+	}
+
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// Server emulates the Form3 API endpoints of the registered collections,
+// including validation messages, version checks and pagination links.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	collections map[string]*collection
+	templates   []*template
+	now         func() time.Time
+}
+
+// NewServer starts a server exposing the accounts, payments and
+// subscriptions endpoints. Call Close once done with it.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+
+	return s
+}
+
+func NewUnstartedServer() *Server {
+	s := &Server{
+		collections: map[string]*collection{},
+		now:         time.Now,
+	}
+
+	s.Register("organisation/accounts", "accounts", validateAccount)
+	s.Register("transaction/payments", "payments", validatePayment)
+	s.Register("transaction/payments/%s/submissions", "payment_submissions", nil)
+	s.Register("transaction/payments/%s/returns", "returns", nil)
+	s.Register("transaction/payments/%s/reversals", "reversals", nil)
+	s.Register("transaction/payments/%s/recalls", "recalls", nil)
+	s.Register("notification/subscriptions", "subscriptions", validateSubscription)
+
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Register exposes a new collection at the given endpoint, relative to the
+// API version. Endpoints containing %s are nested under the records of the
+// collection preceding the placeholder.
+func (s *Server) Register(endpoint, recordType string, validate ValidateFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if strings.Contains(endpoint, "%s") {
+		parts := strings.Split(endpoint, "%s")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+
+		s.templates = append(s.templates, &template{
+			pattern:    regexp.MustCompile("^" + strings.Join(parts, "([^/]+)") + "$"),
+			recordType: recordType,
+			validate:   validate,
+		})
+
+		return
+	}
+
+	s.collections[endpoint] = &collection{
+		recordType: recordType,
+		validate:   validate,
+		records:    map[string]record{},
+	}
+}
+
+// Seed creates the given objects, as if they were POSTed to their endpoint.
+func (s *Server) Seed(objs ...api.Object) error {
+	for _, obj := range objs {
+		endpoint, err := api.Schema.CollectionPath(obj)
+		if err != nil {
+			return err
+		}
+
+		body, err := json.Marshal(api.WrapObject(obj))
+		if err != nil {
+			return err
+		}
+
+		var data struct {
+			Data record `json:"data"`
+		}
+
+		if err := json.Unmarshal(body, &data); err != nil {
+			return err
+		}
+
+		if err := s.seed(endpoint, data.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) seed(endpoint string, data record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	col, exists := s.collection(endpoint)
+	if !exists {
+		return fmt.Errorf("endpoint %s is not served", endpoint)
+	}
+
+	if status, msg := s.create(col, data); status != http.StatusCreated {
+		return fmt.Errorf("couldn't seed %s: %s", data["id"], msg)
+	}
+
+	return nil
+}
+
+// Reset removes every record.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for endpoint, col := range s.collections {
+		if col.nested {
+			delete(s.collections, endpoint)
+			continue
+		}
+
+		col.records = map[string]record{}
+		col.order = nil
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, msg string) {
+	s.writeJSON(w, status, map[string]string{"error_message": msg})
+}
+
+// collection returns the collection served at endpoint. Nested collections
+// are created on first use, as long as their parent record exists.
+func (s *Server) collection(endpoint string) (*collection, bool) {
+	if col, exists := s.collections[endpoint]; exists {
+		return col, true
+	}
+
+	for _, tmpl := range s.templates {
+		if !tmpl.pattern.MatchString(endpoint) {
+			continue
+		}
+
+		parent, id, exists := s.locate(endpoint[:strings.LastIndex(endpoint, "/")])
+		if !exists || parent.records[id] == nil {
+			return nil, false
+		}
+
+		col := &collection{
+			recordType: tmpl.recordType,
+			validate:   tmpl.validate,
+			records:    map[string]record{},
+			nested:     true,
+		}
+
+		s.collections[endpoint] = col
+
+		return col, true
+	}
+
+	return nil, false
+}
+
+// locate finds the collection serving endpoint and the id of the record, if
+// any.
+func (s *Server) locate(endpoint string) (*collection, string, bool) {
+	if col, exists := s.collection(endpoint); exists {
+		return col, "", true
+	}
+
+	idx := strings.LastIndex(endpoint, "/")
+	if idx < 0 {
+		return nil, "", false
+	}
+
+	col, exists := s.collection(endpoint[:idx])
+
+	return col, endpoint[idx+1:], exists
+}
+
+// route finds the collection serving path and the id of the record, if any.
+func (s *Server) route(path string) (*collection, string, bool) {
+	return s.locate(strings.Trim(strings.TrimPrefix(path, "/"+Version+"/"), "/"))
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	col, id, exists := s.route(r.URL.Path)
+	if !exists {
+		s.writeError(w, http.StatusNotFound, "route not found")
+		return
+	}
+
+	if id == "" {
+		switch r.Method {
+		case http.MethodGet:
+			s.list(w, r, col)
+		case http.MethodPost:
+			s.post(w, r, col)
+		default:
+			s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+
+		return
+	}
+
+	if !uuidRegexp.MatchString(id) {
+		s.writeError(w, http.StatusBadRequest, "id is not a valid uuid")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.fetch(w, r, col, id)
+	case http.MethodPatch:
+		s.patch(w, r, col, id)
+	case http.MethodDelete:
+		s.delete(w, r, col, id)
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) fetch(w http.ResponseWriter, r *http.Request, col *collection, id string) {
+	rec, exists := col.records[id]
+	if !exists {
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  rec,
+		"links": map[string]string{"self": r.URL.Path},
+	})
+}
+
+func pageLink(path string, query url.Values, number string, size int) string {
+	q := url.Values{}
+	for key, values := range query {
+		q[key] = values
+	}
+
+	q.Set("page[number]", number)
+	q.Set("page[size]", strconv.Itoa(size))
+
+	return fmt.Sprintf("%s?%s", path, q.Encode())
+}
+
+// list pages through the records in creation order. Like accountapi, filters
+// are accepted but not applied.
+func (s *Server) list(w http.ResponseWriter, r *http.Request, col *collection) {
+	query := r.URL.Query()
+
+	size, err := strconv.Atoi(query.Get("page[size]"))
+	if err != nil || size <= 0 {
+		size = DefaultPageSize
+	}
+
+	lastPage := 0
+	if len(col.order) > 0 {
+		lastPage = (len(col.order) - 1) / size
+	}
+
+	var number int
+
+	switch page := query.Get("page[number]"); page {
+	case "", "first":
+		number = 0
+	case "last":
+		number = lastPage
+	default:
+		number, err = strconv.Atoi(page)
+		if err != nil || number < 0 {
+			s.writeError(w, http.StatusBadRequest, "page[number] must be a positive number")
+			return
+		}
+	}
+
+	data := []record{}
+
+	for i := number * size; i < (number+1)*size && i < len(col.order); i++ {
+		data = append(data, col.records[col.order[i]])
+	}
+
+	links := map[string]string{
+		"self":  pageLink(r.URL.Path, query, strconv.Itoa(number), size),
+		"first": pageLink(r.URL.Path, query, "first", size),
+		"last":  pageLink(r.URL.Path, query, "last", size),
+	}
+
+	if number < lastPage {
+		links["next"] = pageLink(r.URL.Path, query, strconv.Itoa(number+1), size)
+	}
+
+	if number > 0 {
+		links["prev"] = pageLink(r.URL.Path, query, strconv.Itoa(number-1), size)
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"data": data, "links": links})
+}
+
+func decodeData(r *http.Request) (record, error) {
+	var body struct {
+		Data record `json:"data"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	if body.Data == nil {
+		return nil, fmt.Errorf("data in body is required")
+	}
+
+	return body.Data, nil
+}
+
+func (s *Server) post(w http.ResponseWriter, r *http.Request, col *collection) {
+	data, err := decodeData(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status, msg := s.create(col, data)
+	if status != http.StatusCreated {
+		s.writeError(w, status, msg)
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, map[string]interface{}{"data": col.records[data["id"].(string)]})
+}
+
+func (s *Server) create(col *collection, data record) (int, string) {
+	var failures []string
+
+	id, _ := data["id"].(string)
+	if !uuidRegexp.MatchString(id) {
+		failures = append(failures, fmt.Sprintf("id in body must be of type uuid: %q", id))
+	}
+
+	if organisationID, _ := data["organisation_id"].(string); !uuidRegexp.MatchString(organisationID) {
+		failures = append(failures, fmt.Sprintf("organisation_id in body must be of type uuid: %q", organisationID))
+	}
+
+	if recordType, _ := data["type"].(string); recordType != col.recordType {
+		failures = append(failures, fmt.Sprintf("type in body should be one of [%s]", col.recordType))
+	}
+
+	if col.validate != nil {
+		failures = append(failures, col.validate(data)...)
+	}
+
+	if len(failures) > 0 {
+		return http.StatusBadRequest, "validation failure list:\n" + strings.Join(failures, "\n")
+	}
+
+	if _, exists := col.records[id]; exists {
+		return http.StatusConflict, fmt.Sprintf("%s cannot be created as it violates a duplicate constraint", col.name())
+	}
+
+	now := s.now().UTC().Format(time.RFC3339Nano)
+
+	data["version"] = 0
+	data["created_on"] = now
+	data["modified_on"] = now
+
+	col.records[id] = data
+	col.order = append(col.order, id)
+
+	return http.StatusCreated, ""
+}
+
+func (s *Server) patch(w http.ResponseWriter, r *http.Request, col *collection, id string) {
+	rec, exists := col.records[id]
+	if !exists {
+		s.writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+		return
+	}
+
+	data, err := decodeData(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, ok := data["version"].(float64)
+	if !ok || int(version) != rec["version"] {
+		s.writeError(w, http.StatusConflict, "invalid version")
+		return
+	}
+
+	updated := record{}
+	for key, value := range rec {
+		updated[key] = value
+	}
+
+	if attributes, ok := data["attributes"].(map[string]interface{}); ok {
+		merged := map[string]interface{}{}
+
+		if current, ok := rec["attributes"].(map[string]interface{}); ok {
+			for key, value := range current {
+				merged[key] = value
+			}
+		}
+
+		for key, value := range attributes {
+			merged[key] = value
+		}
+
+		updated["attributes"] = merged
+	}
+
+	if col.validate != nil {
+		if failures := col.validate(updated); len(failures) > 0 {
+			s.writeError(w, http.StatusBadRequest, "validation failure list:\n"+strings.Join(failures, "\n"))
+			return
+		}
+	}
+
+	updated["version"] = rec["version"].(int) + 1
+	updated["modified_on"] = s.now().UTC().Format(time.RFC3339Nano)
+
+	col.records[id] = updated
+
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"data": updated})
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request, col *collection, id string) {
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if err != nil || version < 0 {
+		s.writeError(w, http.StatusBadRequest, "invalid version number")
+		return
+	}
+
+	rec, exists := col.records[id]
+	if !exists {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if rec["version"] != version {
+		s.writeError(w, http.StatusNotFound, "invalid version")
+		return
+	}
+
+	delete(col.records, id)
+
+	for i, current := range col.order {
+		if current == id {
+			col.order = append(col.order[:i], col.order[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
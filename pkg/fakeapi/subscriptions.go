@@ -0,0 +1,31 @@
+package fakeapi
+
+import "fmt"
+
+var callbackTransports = map[string]bool{
+	"http":  true,
+	"queue": true,
+}
+
+// validateSubscription mirrors the checks the notification API runs on
+// subscription attributes.
+func validateSubscription(data record) []string {
+	attributes, ok := data["attributes"].(map[string]interface{})
+	if !ok {
+		return []string{"attributes in body is required"}
+	}
+
+	var failures []string
+
+	for _, field := range []string{"callback_uri", "record_type", "event_type"} {
+		if value, _ := attributes[field].(string); value == "" {
+			failures = append(failures, fmt.Sprintf("%s in body is required", field))
+		}
+	}
+
+	if transport, _ := attributes["callback_transport"].(string); !callbackTransports[transport] {
+		failures = append(failures, "callback_transport in body should be one of [http queue]")
+	}
+
+	return failures
+}
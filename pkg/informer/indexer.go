@@ -0,0 +1,176 @@
+package informer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var ErrUnknownIndex = errors.New("unknown index")
+
+// IndexFunc returns the values obj is indexed under. Empty values are
+// ignored.
+type IndexFunc func(obj api.Object) []string
+
+// Indexers maps index names to the functions computing them.
+type Indexers map[string]IndexFunc
+
+// Indexer is a thread-safe store of objects keyed by ID, with secondary
+// indices. Stored objects are shared with callers and must not be modified.
+type Indexer struct {
+	mu       sync.RWMutex
+	items    map[string]api.Object
+	indexers Indexers
+	indices  map[string]map[string]map[string]bool
+}
+
+func NewIndexer(indexers Indexers) *Indexer {
+	indices := make(map[string]map[string]map[string]bool, len(indexers))
+	for name := range indexers {
+		indices[name] = map[string]map[string]bool{}
+	}
+
+	return &Indexer{
+		items:    map[string]api.Object{},
+		indexers: indexers,
+		indices:  indices,
+	}
+}
+
+func (i *Indexer) index(id string, obj api.Object) {
+	for name, indexFunc := range i.indexers {
+		for _, value := range indexFunc(obj) {
+			if value == "" {
+				continue
+			}
+
+			if i.indices[name][value] == nil {
+				i.indices[name][value] = map[string]bool{}
+			}
+
+			i.indices[name][value][id] = true
+		}
+	}
+}
+
+func (i *Indexer) unindex(id string, obj api.Object) {
+	for name, indexFunc := range i.indexers {
+		for _, value := range indexFunc(obj) {
+			delete(i.indices[name][value], id)
+
+			if len(i.indices[name][value]) == 0 {
+				delete(i.indices[name], value)
+			}
+		}
+	}
+}
+
+// Add stores obj, replacing any object with the same ID.
+func (i *Indexer) Add(obj api.Object) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.add(obj)
+}
+
+func (i *Indexer) add(obj api.Object) {
+	id := obj.GetID()
+
+	if old, exists := i.items[id]; exists {
+		i.unindex(id, old)
+	}
+
+	i.items[id] = obj
+	i.index(id, obj)
+}
+
+func (i *Indexer) Delete(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.delete(id)
+}
+
+func (i *Indexer) delete(id string) {
+	if old, exists := i.items[id]; exists {
+		i.unindex(id, old)
+		delete(i.items, id)
+	}
+}
+
+// Replace swaps the content of the store for objs.
+func (i *Indexer) Replace(objs []api.Object) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.items = map[string]api.Object{}
+	for name := range i.indices {
+		i.indices[name] = map[string]map[string]bool{}
+	}
+
+	for _, obj := range objs {
+		i.add(obj)
+	}
+}
+
+func (i *Indexer) Get(id string) (api.Object, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	obj, exists := i.items[id]
+
+	return obj, exists
+}
+
+// List returns every stored object, sorted by ID.
+func (i *Indexer) List() []api.Object {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	ids := make([]string, 0, len(i.items))
+	for id := range i.items {
+		ids = append(ids, id)
+	}
+
+	return i.byIDs(ids)
+}
+
+// ByIndex returns the objects indexed under value by the named index,
+// sorted by ID.
+func (i *Indexer) ByIndex(name, value string) ([]api.Object, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	index, exists := i.indices[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownIndex, name)
+	}
+
+	ids := make([]string, 0, len(index[value]))
+	for id := range index[value] {
+		ids = append(ids, id)
+	}
+
+	return i.byIDs(ids), nil
+}
+
+func (i *Indexer) byIDs(ids []string) []api.Object {
+	sort.Strings(ids)
+
+	objs := make([]api.Object, 0, len(ids))
+	for _, id := range ids {
+		objs = append(objs, i.items[id])
+	}
+
+	return objs
+}
+
+func (i *Indexer) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.items)
+}
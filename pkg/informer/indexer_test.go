@@ -0,0 +1,40 @@
+package informer
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("Indexer", func() {
+	It("should keep indices up to date", func() {
+		indexer := NewIndexer(AccountIndexers)
+
+		account := newAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300", "1")
+		account.Attributes.IBAN = "GB82WEST12345698765432"
+		indexer.Add(account)
+
+		lister := NewAccountLister(indexer)
+
+		byIBAN, err := lister.ByIBAN("GB82WEST12345698765432")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(byIBAN).To(Equal([]*api.Account{account}))
+
+		moved := newAccount(account.ID, "400302", "1")
+		indexer.Add(moved)
+
+		Expect(lister.ByBankID("400300")).To(BeEmpty())
+		Expect(lister.ByIBAN("GB82WEST12345698765432")).To(BeEmpty())
+		Expect(lister.ByBankID("400302")).To(HaveLen(1))
+
+		indexer.Delete(account.ID)
+		Expect(lister.List()).To(BeEmpty())
+		Expect(lister.ByCustomerID("1")).To(BeEmpty())
+	})
+
+	It("should reject unknown indices", func() {
+		_, err := NewIndexer(nil).ByIndex("bank_id", "400300")
+		Expect(err).To(MatchError(ErrUnknownIndex))
+	})
+})
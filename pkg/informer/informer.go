@@ -0,0 +1,292 @@
+// Package informer keeps a local, indexed cache of Form3 resources fresh by
+// listing them periodically, and notifies handlers of the changes, like
+// client-go informers do for Kubernetes objects.
+package informer
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+)
+
+type EventType string
+
+const (
+	Added    EventType = "ADDED"
+	Modified EventType = "MODIFIED"
+	Deleted  EventType = "DELETED"
+)
+
+// Event describes a change found between two lists. Old is set for
+// Modified events.
+type Event struct {
+	Type   EventType
+	Object api.Object
+	Old    api.Object
+}
+
+// EventHandlerFuncs is notified of changes. Nil functions are skipped.
+type EventHandlerFuncs struct {
+	AddFunc    func(obj api.Object)
+	UpdateFunc func(old, obj api.Object)
+	DeleteFunc func(obj api.Object)
+}
+
+func (h *EventHandlerFuncs) handle(event *Event) {
+	switch event.Type {
+	case Added:
+		if h.AddFunc != nil {
+			h.AddFunc(event.Object)
+		}
+	case Modified:
+		if h.UpdateFunc != nil {
+			h.UpdateFunc(event.Old, event.Object)
+		}
+	case Deleted:
+		if h.DeleteFunc != nil {
+			h.DeleteFunc(event.Object)
+		}
+	}
+}
+
+// Informer lists a resource every ResyncPeriod, diffs the result with its
+// cache by ID and version, and notifies handlers of the changes.
+type Informer struct {
+	ResyncPeriod time.Duration
+	ListOptions  *pkg.ListOptions
+	// ErrorHandler is called when listing fails. The cache is kept as is
+	// until the next successful list.
+	ErrorHandler func(error)
+
+	client  pkg.Client
+	list    reflect.Value
+	indexer *Indexer
+
+	// resyncMu serialises resyncs, so each one diffs against the cache left
+	// by the previous one.
+	resyncMu sync.Mutex
+
+	mu       sync.RWMutex
+	handlers []*EventHandlerFuncs
+	synced   bool
+	syncedCh chan struct{}
+}
+
+// NewInformer watches the resources listed through list, e.g.
+// &api.AccountList{}, which is used as a template and isn't modified.
+func NewInformer(client pkg.Client, list api.Object, resyncPeriod time.Duration, indexers Indexers) (*Informer, error) {
+	v, err := api.EnforcePtr(list)
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.FieldByName("Items").IsValid() {
+		return nil, pkg.ErrInvalidObjectType
+	}
+
+	return &Informer{
+		ResyncPeriod: resyncPeriod,
+		client:       client,
+		list:         v,
+		indexer:      NewIndexer(indexers),
+		syncedCh:     make(chan struct{}),
+	}, nil
+}
+
+// NewAccountInformer watches accounts, indexed by AccountIndexers.
+func NewAccountInformer(client pkg.Client, resyncPeriod time.Duration) *Informer {
+	informer, err := NewInformer(client, &api.AccountList{}, resyncPeriod, AccountIndexers)
+	if err != nil {
+		panic(err)
+	}
+
+	return informer
+}
+
+func (i *Informer) Indexer() *Indexer {
+	return i.indexer
+}
+
+// AddEventHandler registers handler. Handlers are called sequentially, from
+// the goroutine running the informer.
+func (i *Informer) AddEventHandler(handler EventHandlerFuncs) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.handlers = append(i.handlers, &handler)
+}
+
+// HasSynced reports whether the cache has been filled by a first list.
+func (i *Informer) HasSynced() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.synced
+}
+
+// WaitForCacheSync blocks until the first list completes or ctx is done.
+func (i *Informer) WaitForCacheSync(ctx context.Context) bool {
+	select {
+	case <-i.syncedCh:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Run lists the resource right away and then every ResyncPeriod, until ctx is
+// done. With a ResyncPeriod of 0 or less, it lists once and then only when
+// Resync is called.
+func (i *Informer) Run(ctx context.Context) {
+	// Receiving from a nil channel blocks, so the loop only ends with ctx.
+	var tick <-chan time.Time
+
+	if i.ResyncPeriod > 0 {
+		ticker := time.NewTicker(i.ResyncPeriod)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		if err := i.Resync(ctx); err != nil && ctx.Err() == nil && i.ErrorHandler != nil {
+			i.ErrorHandler(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		}
+	}
+}
+
+// Resync lists the resource once, updates the cache and notifies the
+// handlers of the differences. Concurrent calls, including the ones made by
+// Run, happen one after the other, so handlers mustn't call Resync.
+func (i *Informer) Resync(ctx context.Context) error {
+	i.resyncMu.Lock()
+	defer i.resyncMu.Unlock()
+
+	objs, err := i.listObjects(ctx)
+	if err != nil {
+		return err
+	}
+
+	events := diff(i.indexer.List(), objs)
+
+	i.indexer.Replace(objs)
+	i.markSynced()
+
+	i.mu.RLock()
+	handlers := make([]*EventHandlerFuncs, len(i.handlers))
+	copy(handlers, i.handlers)
+	i.mu.RUnlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler.handle(event)
+		}
+	}
+
+	return nil
+}
+
+func (i *Informer) markSynced() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !i.synced {
+		i.synced = true
+		close(i.syncedCh)
+	}
+}
+
+// listObjects lists into a fresh copy of the template, so cached objects are
+// never overwritten by later lists.
+func (i *Informer) listObjects(ctx context.Context) ([]api.Object, error) {
+	list := reflect.New(i.list.Type())
+	list.Elem().Set(i.list)
+
+	if err := i.client.List(ctx, list.Interface().(api.Object), i.ListOptions); err != nil {
+		return nil, err
+	}
+
+	items := list.Elem().FieldByName("Items")
+	objs := make([]api.Object, 0, items.Len())
+
+	for idx := 0; idx < items.Len(); idx++ {
+		objs = append(objs, items.Index(idx).Addr().Interface().(api.Object))
+	}
+
+	return objs, nil
+}
+
+// diff compares the cached objects with the listed ones. Added and Modified
+// events follow the order of the list, Deleted ones come last.
+func diff(cached, listed []api.Object) []*Event {
+	previous := make(map[string]api.Object, len(cached))
+	for _, obj := range cached {
+		previous[obj.GetID()] = obj
+	}
+
+	var events []*Event
+
+	for _, obj := range listed {
+		old, exists := previous[obj.GetID()]
+
+		switch {
+		case !exists:
+			events = append(events, &Event{Type: Added, Object: obj})
+		case old.GetVersion() != obj.GetVersion():
+			events = append(events, &Event{Type: Modified, Object: obj, Old: old})
+		}
+
+		delete(previous, obj.GetID())
+	}
+
+	for _, obj := range cached {
+		if _, removed := previous[obj.GetID()]; removed {
+			events = append(events, &Event{Type: Deleted, Object: obj})
+		}
+	}
+
+	return events
+}
+
+// Watch runs an informer for list until ctx is done, and sends its events on
+// the returned channel, which is closed once the informer stops. The channel
+// must be drained, since the informer waits for each event to be received.
+func Watch(ctx context.Context, client pkg.Client, list api.Object, resyncPeriod time.Duration) (<-chan Event, error) {
+	informer, err := NewInformer(client, list, resyncPeriod, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+
+	send := func(event Event) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	informer.AddEventHandler(EventHandlerFuncs{
+		AddFunc:    func(obj api.Object) { send(Event{Type: Added, Object: obj}) },
+		UpdateFunc: func(old, obj api.Object) { send(Event{Type: Modified, Object: obj, Old: old}) },
+		DeleteFunc: func(obj api.Object) { send(Event{Type: Deleted, Object: obj}) },
+	})
+
+	go func() {
+		defer close(events)
+
+		informer.Run(ctx)
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,214 @@
+package informer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fake"
+)
+
+func newAccount(id, bankID, customerID string) *api.Account {
+	account := api.NewAccount(id, 0)
+	account.Attributes.BankID = bankID
+	account.Attributes.CustomerID = customerID
+
+	return account
+}
+
+var _ = Describe("Informer", func() {
+	var (
+		client   *fake.Client
+		informer *Informer
+		events   []string
+	)
+
+	record := func(eventType EventType) func(obj api.Object) {
+		return func(obj api.Object) {
+			events = append(events, string(eventType)+" "+obj.GetID())
+		}
+	}
+
+	BeforeEach(func() {
+		client = fake.NewClient(
+			newAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300", "1"),
+			newAccount("bd27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300", "2"),
+		)
+
+		informer = NewAccountInformer(client, time.Hour)
+		events = nil
+
+		informer.AddEventHandler(EventHandlerFuncs{
+			AddFunc:    record(Added),
+			UpdateFunc: func(old, obj api.Object) { record(Modified)(obj) },
+			DeleteFunc: record(Deleted),
+		})
+	})
+
+	It("should fill the cache and notify additions", func() {
+		Expect(informer.HasSynced()).To(BeFalse())
+		Expect(informer.Resync(context.TODO())).To(Succeed())
+		Expect(informer.HasSynced()).To(BeTrue())
+
+		Expect(events).To(Equal([]string{
+			"ADDED ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+			"ADDED bd27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		}))
+
+		accounts, err := NewAccountLister(informer.Indexer()).ByBankID("400300")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(accounts).To(HaveLen(2))
+	})
+
+	It("should diff lists by ID and version", func() {
+		Expect(informer.Resync(context.TODO())).To(Succeed())
+		events = nil
+
+		updated := newAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400302", "1")
+		Expect(client.Update(context.TODO(), updated)).To(Succeed())
+		Expect(client.Delete(context.TODO(), api.NewAccount("bd27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0))).To(Succeed())
+		Expect(client.Create(context.TODO(), newAccount("cd27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300", "3"))).To(Succeed())
+
+		Expect(informer.Resync(context.TODO())).To(Succeed())
+
+		Expect(events).To(Equal([]string{
+			"MODIFIED ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+			"ADDED cd27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+			"DELETED bd27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		}))
+
+		lister := NewAccountLister(informer.Indexer())
+
+		account, exists := lister.Get("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc")
+		Expect(exists).To(BeTrue())
+		Expect(account.Version).To(Equal(1))
+
+		byBankID, err := lister.ByBankID("400300")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(byBankID).To(HaveLen(1))
+		Expect(byBankID[0].ID).To(Equal("cd27e265-9605-4b4b-a0e5-3003ea9cc4dc"))
+
+		byCustomerID, err := lister.ByCustomerID("2")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(byCustomerID).To(BeEmpty())
+	})
+
+	It("should notify each change once when resyncing concurrently", func() {
+		const resyncs = 8
+
+		// Lists are held until every resync which can list at once did.
+		arrived, release := make(chan struct{}, resyncs), make(chan struct{})
+		client.PrependReactor(fake.VerbList, fake.Any, func(action fake.Action) (bool, error) {
+			arrived <- struct{}{}
+			<-release
+
+			return false, nil
+		})
+
+		var wg sync.WaitGroup
+
+		for n := 0; n < resyncs; n++ {
+			wg.Add(1)
+
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				Expect(informer.Resync(context.TODO())).To(Succeed())
+			}()
+		}
+
+		Eventually(arrived).Should(Receive())
+		Consistently(arrived, 50*time.Millisecond).ShouldNot(Receive())
+		close(release)
+
+		wg.Wait()
+
+		Expect(events).To(Equal([]string{
+			"ADDED ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+			"ADDED bd27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		}))
+	})
+
+	It("should keep the cache when listing fails", func() {
+		Expect(informer.Resync(context.TODO())).To(Succeed())
+
+		boom := errors.New("boom")
+		client.PrependReactor(fake.VerbList, fake.Any, func(action fake.Action) (bool, error) {
+			return true, boom
+		})
+
+		Expect(informer.Resync(context.TODO())).To(MatchError(boom))
+		Expect(informer.Indexer().Len()).To(Equal(2))
+	})
+
+	It("should resync until the context is done", func() {
+		informer.ResyncPeriod = 10 * time.Millisecond
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			informer.Run(ctx)
+		}()
+
+		Expect(informer.WaitForCacheSync(ctx)).To(BeTrue())
+		Eventually(func() int { return len(client.Actions()) }).Should(BeNumerically(">=", 3))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("should list once without a resync period", func() {
+		informer = NewAccountInformer(client, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			informer.Run(ctx)
+		}()
+
+		Expect(informer.WaitForCacheSync(ctx)).To(BeTrue())
+		Consistently(func() int { return len(client.Actions()) }, 50*time.Millisecond).Should(Equal(1))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+})
+
+var _ = Describe("Watch", func() {
+	It("should stream the changes", func() {
+		client := fake.NewClient(newAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "400300", "1"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events, err := Watch(ctx, client, &api.AccountList{}, 10*time.Millisecond)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		event := <-events
+		Expect(event.Type).To(Equal(Added))
+		Expect(event.Object.GetID()).To(Equal("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"))
+
+		Expect(client.Delete(context.TODO(), api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0))).To(Succeed())
+
+		event = <-events
+		Expect(event.Type).To(Equal(Deleted))
+
+		cancel()
+		Eventually(events).Should(BeClosed())
+	})
+
+	It("should reject objects that aren't lists", func() {
+		_, err := Watch(context.TODO(), fake.NewClient(), &api.Account{}, time.Second)
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,106 @@
+package informer
+
+import (
+	"github.com/vtemian/form3/pkg/api"
+)
+
+// Index names of AccountIndexers.
+const (
+	IndexBankID     = "bank_id"
+	IndexIBAN       = "iban"
+	IndexCustomerID = "customer_id"
+)
+
+func accountIndex(value func(*api.Account) string) IndexFunc {
+	return func(obj api.Object) []string {
+		account, ok := obj.(*api.Account)
+		if !ok {
+			return nil
+		}
+
+		return []string{value(account)}
+	}
+}
+
+// AccountIndexers indexes accounts by bank ID, IBAN and customer ID.
+var AccountIndexers = Indexers{
+	IndexBankID:     accountIndex(func(a *api.Account) string { return a.Attributes.BankID }),
+	IndexIBAN:       accountIndex(func(a *api.Account) string { return string(a.Attributes.IBAN) }),
+	IndexCustomerID: accountIndex(func(a *api.Account) string { return a.Attributes.CustomerID }),
+}
+
+// Lister reads objects of type T from an Indexer. Returned objects are shared
+// with the cache and must not be modified.
+type Lister[T any, PT interface {
+	*T
+	api.Object
+}] struct {
+	indexer *Indexer
+}
+
+func NewLister[T any, PT interface {
+	*T
+	api.Object
+}](indexer *Indexer) *Lister[T, PT] {
+	return &Lister[T, PT]{indexer: indexer}
+}
+
+func typed[T any, PT interface {
+	*T
+	api.Object
+}](objs []api.Object) []PT {
+	result := make([]PT, 0, len(objs))
+
+	for _, obj := range objs {
+		if typedObj, ok := obj.(PT); ok {
+			result = append(result, typedObj)
+		}
+	}
+
+	return result
+}
+
+func (l *Lister[T, PT]) Get(id string) (PT, bool) {
+	obj, exists := l.indexer.Get(id)
+	if !exists {
+		return nil, false
+	}
+
+	typedObj, ok := obj.(PT)
+
+	return typedObj, ok
+}
+
+func (l *Lister[T, PT]) List() []PT {
+	return typed[T, PT](l.indexer.List())
+}
+
+func (l *Lister[T, PT]) ByIndex(name, value string) ([]PT, error) {
+	objs, err := l.indexer.ByIndex(name, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return typed[T, PT](objs), nil
+}
+
+// AccountLister queries a cache of accounts built with AccountIndexers.
+type AccountLister struct {
+	*Lister[api.Account, *api.Account]
+}
+
+func NewAccountLister(indexer *Indexer) *AccountLister {
+	return &AccountLister{Lister: NewLister[api.Account](indexer)}
+}
+
+func (l *AccountLister) ByBankID(bankID string) ([]*api.Account, error) {
+	return l.ByIndex(IndexBankID, bankID)
+}
+
+func (l *AccountLister) ByIBAN(iban api.IBAN) ([]*api.Account, error) {
+	return l.ByIndex(IndexIBAN, string(iban))
+}
+
+func (l *AccountLister) ByCustomerID(customerID string) ([]*api.Account, error) {
+	return l.ByIndex(IndexCustomerID, customerID)
+}
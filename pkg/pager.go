@@ -0,0 +1,228 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+// PageFunc fetches the page found at cursor, stores its items in the Items
+// field of list and returns the cursor of the following page, or "" if it was
+// the last one.
+type PageFunc func(ctx context.Context, list api.Object, cursor string) (string, error)
+
+// Pager walks a list endpoint one page at a time. Only the current page is
+// kept in memory and every response is closed as soon as it's decoded.
+//
+//	pager := client.Pager(&api.AccountList{}, nil)
+//	for pager.Next(ctx) {
+//		// pager.List().(*api.AccountList).Items holds the current page
+//	}
+//	if err := pager.Err(); err != nil { ... }
+type Pager struct {
+	list   api.Object
+	cursor string
+	fetch  PageFunc
+	err    error
+}
+
+func NewPager(list api.Object, cursor string, fetch PageFunc) *Pager {
+	return &Pager{list: list, cursor: cursor, fetch: fetch}
+}
+
+// Next fetches the following page. It returns false once all the pages were
+// consumed or an error occurred.
+func (p *Pager) Next(ctx context.Context) bool {
+	if p.err != nil || p.cursor == "" {
+		return false
+	}
+
+	next, err := p.fetch(ctx, p.list, p.cursor)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	p.cursor = next
+
+	return true
+}
+
+// List returns the list object holding the items of the current page.
+func (p *Pager) List() api.Object {
+	return p.list
+}
+
+// Cursor points to the page following the current one. It can be passed as
+// ListOptions.Cursor to resume listing later on, and it's empty once the last
+// page was fetched.
+func (p *Pager) Cursor() string {
+	return p.cursor
+}
+
+func (p *Pager) Err() error {
+	return p.err
+}
+
+// ListIter yields the items of a list endpoint one at a time, fetching pages
+// as needed.
+type ListIter struct {
+	pager *Pager
+	items reflect.Value
+	index int
+	item  api.Object
+}
+
+func NewListIter(pager *Pager) *ListIter {
+	return &ListIter{pager: pager, index: -1}
+}
+
+func (it *ListIter) Next(ctx context.Context) bool {
+	it.index++
+
+	for !it.items.IsValid() || it.index >= it.items.Len() {
+		if !it.pager.Next(ctx) {
+			it.item = nil
+			return false
+		}
+
+		v, err := api.EnforcePtr(it.pager.List())
+		if err != nil {
+			it.pager.err = err
+			return false
+		}
+
+		it.items = v.FieldByName("Items")
+		it.index = 0
+	}
+
+	it.item = it.items.Index(it.index).Addr().Interface().(api.Object)
+
+	return true
+}
+
+// Item returns a pointer to the current item, e.g. *api.Account.
+func (it *ListIter) Item() api.Object {
+	return it.item
+}
+
+// Cursor points to the page following the one the current item belongs to.
+func (it *ListIter) Cursor() string {
+	return it.pager.Cursor()
+}
+
+func (it *ListIter) Err() error {
+	return it.pager.Err()
+}
+
+func (c *Form3Client) Pager(obj api.Object, listOptions *ListOptions) *Pager {
+	pager := NewPager(obj, "", c.fetchPage)
+
+	v, err := api.EnforcePtr(obj)
+	if err != nil {
+		pager.err = err
+		return pager
+	}
+
+	if !v.FieldByName("Items").IsValid() {
+		pager.err = ErrInvalidObjectType
+		return pager
+	}
+
+	if listOptions != nil && listOptions.Cursor != "" {
+		pager.cursor = c.resolve(listOptions.Cursor)
+		return pager
+	}
+
+	url, err := c.url(obj)
+	if err != nil {
+		pager.err = err
+		return pager
+	}
+
+	if listOptions != nil {
+		query, err := listOptions.Values(obj)
+		if err != nil {
+			pager.err = err
+			return pager
+		}
+
+		if len(query) > 0 {
+			url = fmt.Sprintf("%s?%s", url, query.Encode())
+		}
+	}
+
+	pager.cursor = url
+
+	return pager
+}
+
+func (c *Form3Client) ListIter(obj api.Object, listOptions *ListOptions) *ListIter {
+	return NewListIter(c.Pager(obj, listOptions))
+}
+
+// resolve turns a link returned by upstream into an absolute url.
+func (c *Form3Client) resolve(link string) string {
+	if strings.Contains(link, "://") {
+		return link
+	}
+
+	return fmt.Sprintf("%s/%s", c.BaseURL, strings.TrimPrefix(link, "/"))
+}
+
+func (c *Form3Client) fetchPage(ctx context.Context, obj api.Object, url string) (string, error) {
+	v, err := api.EnforcePtr(obj)
+	if err != nil {
+		return "", err
+	}
+
+	items := v.FieldByName("Items")
+
+	objListType := reflect.StructOf([]reflect.StructField{
+		{
+			Name: "Data",
+			Type: items.Type(),
+			Tag:  `json:"data"`,
+		},
+		{
+			Name: "Links",
+			Type: reflect.TypeOf(api.Links{}),
+			Tag:  `json:"links"`,
+		},
+	})
+	objList := reflect.New(objListType)
+
+	resp, err := c.execute(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if !c.isOK(resp) {
+		return "", c.err(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(objList.Interface()); err != nil {
+		return "", err
+	}
+
+	data := objList.Elem().FieldByName("Data")
+	if data.IsNil() {
+		data = reflect.MakeSlice(items.Type(), 0, 0)
+	}
+
+	items.Set(data)
+
+	links := objList.Elem().FieldByName("Links").Interface().(api.Links)
+	if links.Next == "" || links.Next == links.Self {
+		return "", nil
+	}
+
+	return c.resolve(links.Next), nil
+}
@@ -0,0 +1,162 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("Pager", func() {
+	var (
+		server   *httptest.Server
+		accounts []api.Account
+		requests int
+	)
+
+	BeforeEach(func() {
+		requests = 0
+		accounts = nil
+
+		for i := 0; i < 5; i++ {
+			accounts = append(accounts, *api.NewAccount(fmt.Sprintf("ad27e265-9605-4b4b-a0e5-3003ea9cc4d%d", i), 0))
+		}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+
+			number, _ := strconv.Atoi(r.URL.Query().Get("page[number]"))
+			size, _ := strconv.Atoi(r.URL.Query().Get("page[size]"))
+			if size == 0 {
+				size = 100
+			}
+
+			start, end := number*size, (number+1)*size
+			if end > len(accounts) {
+				end = len(accounts)
+			}
+
+			if start >= end {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			link := func(page int) string {
+				return fmt.Sprintf("/v1/organisation/accounts?page%%5Bnumber%%5D=%d&page%%5Bsize%%5D=%d", page, size)
+			}
+
+			links := api.Links{Self: link(number), First: link(0), Last: link((len(accounts) - 1) / size)}
+			if end < len(accounts) {
+				links.Next = link(number + 1)
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"data":  accounts[start:end],
+				"links": links,
+			})
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should yield one page at a time", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		list := &api.AccountList{}
+		pager := form3Client.Pager(list, &ListOptions{PageSize: 2})
+
+		var pages [][]string
+
+		for pager.Next(context.TODO()) {
+			var ids []string
+			for _, account := range list.Items {
+				ids = append(ids, account.ID)
+			}
+
+			pages = append(pages, ids)
+		}
+
+		Expect(pager.Err()).ShouldNot(HaveOccurred())
+		Expect(pages).To(HaveLen(3))
+		Expect(pages[0]).To(Equal([]string{accounts[0].ID, accounts[1].ID}))
+		Expect(pages[2]).To(Equal([]string{accounts[4].ID}))
+		Expect(pager.Cursor()).To(BeEmpty())
+	})
+
+	It("should resume from a cursor", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		pager := form3Client.Pager(&api.AccountList{}, &ListOptions{PageSize: 2})
+		Expect(pager.Next(context.TODO())).To(BeTrue())
+
+		cursor := pager.Cursor()
+		Expect(cursor).NotTo(BeEmpty())
+
+		list := &api.AccountList{}
+		resumed := form3Client.Pager(list, &ListOptions{Cursor: cursor})
+		Expect(resumed.Next(context.TODO())).To(BeTrue())
+
+		Expect(list.Items).To(HaveLen(2))
+		Expect(list.Items[0].ID).To(Equal(accounts[2].ID))
+	})
+
+	It("should iterate over items and stop early", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		iter := form3Client.ListIter(&api.AccountList{}, &ListOptions{PageSize: 2})
+
+		var ids []string
+
+		for iter.Next(context.TODO()) {
+			ids = append(ids, iter.Item().(*api.Account).ID)
+			if len(ids) == 3 {
+				break
+			}
+		}
+
+		Expect(iter.Err()).ShouldNot(HaveOccurred())
+		Expect(ids).To(Equal([]string{accounts[0].ID, accounts[1].ID, accounts[2].ID}))
+		Expect(requests).To(Equal(2))
+	})
+
+	It("should iterate over every item", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		iter := form3Client.ListIter(&api.AccountList{}, &ListOptions{PageSize: 2})
+
+		count := 0
+		for iter.Next(context.TODO()) {
+			count++
+		}
+
+		Expect(iter.Err()).ShouldNot(HaveOccurred())
+		Expect(count).To(Equal(len(accounts)))
+	})
+
+	It("should surface upstream errors", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		pager := form3Client.Pager(&api.AccountList{}, &ListOptions{PageNumber: 10, PageSize: 2})
+
+		Expect(pager.Next(context.TODO())).To(BeFalse())
+		Expect(IsNotFound(pager.Err())).To(BeTrue())
+	})
+
+	It("should reject objects without items", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		iter := form3Client.ListIter(&api.Account{}, nil)
+
+		Expect(iter.Next(context.TODO())).To(BeFalse())
+		Expect(iter.Err()).To(Equal(ErrInvalidObjectType))
+	})
+})
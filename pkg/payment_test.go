@@ -0,0 +1,196 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+func newPayment(id string) *api.Payment {
+	payment := api.NewPayment(id, 0)
+	payment.Type = "payments"
+	payment.OrganisationID = "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb"
+	payment.Attributes = api.PaymentAttributes{
+		Amount:   "100.21",
+		Currency: "GBP",
+		BeneficiaryParty: &api.PaymentParty{
+			AccountNumber: "31926819",
+			BankID:        "403000",
+			BankIDCode:    "GBDSC",
+			Name:          "Wilfred Jeremiah Owens",
+		},
+		DebtorParty: &api.PaymentParty{
+			AccountNumber: "GB29XABC10161234567801",
+			BankID:        "203301",
+			BankIDCode:    "GBDSC",
+			Name:          "Emelia Jane Brown",
+		},
+		ChargesInformation: &api.ChargesInformation{
+			BearerCode:    api.ChargeBearerShared,
+			SenderCharges: []api.Charge{{Amount: "5.00", Currency: "GBP"}},
+		},
+		PaymentScheme:  api.PaymentSchemeFPS,
+		ProcessingDate: "2017-01-18",
+		Reference:      "Payment for Em's piano lessons",
+	}
+
+	return payment
+}
+
+// The payments specs always run against the stand-in server, since
+// accountapi doesn't serve payments.
+var _ = Describe("payments", func() {
+	var (
+		server      *fakeapi.Server
+		form3Client Client
+	)
+
+	BeforeEach(func() {
+		server = fakeapi.NewServer()
+		form3Client = NewClient(WithBaseURL(server.URL))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create and fetch payments", func() {
+		payment := newPayment("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")
+
+		Expect(form3Client.Create(context.TODO(), payment)).To(Succeed())
+
+		fetched := api.NewPayment(payment.ID, 0)
+		Expect(form3Client.Fetch(context.TODO(), fetched)).To(Succeed())
+
+		Expect(fetched.Attributes).To(Equal(payment.Attributes))
+		Expect(fetched.Type).To(Equal("payments"))
+	})
+
+	It("should list payments page by page", func() {
+		for i := 0; i < 5; i++ {
+			Expect(server.Seed(newPayment(fmt.Sprintf("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec4%d", i)))).To(Succeed())
+		}
+
+		list := &api.PaymentList{}
+		Expect(form3Client.List(context.TODO(), list, &ListOptions{PageSize: 2})).To(Succeed())
+		Expect(list.Items).To(HaveLen(5))
+		Expect(list.Items[4].ID).To(Equal("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec44"))
+
+		payments, err := form3Client.Payments().List(context.TODO(), nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(payments).To(HaveLen(5))
+	})
+
+	It("should delete payments", func() {
+		payment := newPayment("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")
+		Expect(server.Seed(payment)).To(Succeed())
+
+		Expect(form3Client.Payments().Delete(context.TODO(), payment.ID, 0)).To(Succeed())
+
+		_, err := form3Client.Payments().Get(context.TODO(), payment.ID)
+		Expect(IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should return the validation failures of the server", func() {
+		payment := newPayment("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")
+		payment.Attributes.Amount = "100,21"
+		payment.Attributes.PaymentScheme = "SWIFT"
+
+		err := form3Client.Create(context.TODO(), payment)
+
+		Expect(IsBadRequest(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("amount in body should match")))
+		Expect(err).To(MatchError(ContainSubstring("payment_scheme in body should be one of [FPS BACS CHAPS SEPACT]")))
+	})
+
+	It("should reject duplicate payments", func() {
+		payment := newPayment("4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43")
+		Expect(server.Seed(payment)).To(Succeed())
+
+		err := form3Client.Create(context.TODO(), payment)
+
+		Expect(IsConflict(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("Payment cannot be created as it violates a duplicate constraint")))
+	})
+})
+
+var _ = Describe("payment sub-resources", func() {
+	const (
+		paymentID = "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
+		otherID   = "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec44"
+	)
+
+	var (
+		server      *fakeapi.Server
+		form3Client Client
+	)
+
+	newSubmission := func(paymentID, id string) *api.PaymentSubmission {
+		submission := api.NewPaymentSubmission(paymentID, id, 0)
+		submission.Type = "payment_submissions"
+		submission.OrganisationID = "743d5b63-8e6f-432e-a8fa-c5d8d2ee5fcb"
+
+		return submission
+	}
+
+	BeforeEach(func() {
+		server = fakeapi.NewServer()
+		form3Client = NewClient(WithBaseURL(server.URL))
+
+		Expect(server.Seed(newPayment(paymentID), newPayment(otherID))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create and fetch resources nested under their payment", func() {
+		submission := newSubmission(paymentID, "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5")
+		submission.Attributes.Status = "accepted"
+
+		Expect(form3Client.Create(context.TODO(), submission)).To(Succeed())
+
+		fetched := api.NewPaymentSubmission(paymentID, submission.ID, 0)
+		Expect(form3Client.Fetch(context.TODO(), fetched)).To(Succeed())
+		Expect(fetched.Attributes.Status).To(Equal("accepted"))
+		Expect(fetched.GetParentID()).To(Equal(paymentID))
+
+		elsewhere := api.NewPaymentSubmission(otherID, submission.ID, 0)
+		Expect(IsNotFound(form3Client.Fetch(context.TODO(), elsewhere))).To(BeTrue())
+	})
+
+	It("should only list the resources of the given payment", func() {
+		Expect(server.Seed(
+			newSubmission(paymentID, "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5"),
+			newSubmission(paymentID, "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc6"),
+			newSubmission(otherID, "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc7"),
+		)).To(Succeed())
+
+		list := &api.PaymentSubmissionList{PaymentID: paymentID}
+		Expect(form3Client.List(context.TODO(), list, nil)).To(Succeed())
+		Expect(list.Items).To(HaveLen(2))
+
+		list = &api.PaymentSubmissionList{PaymentID: otherID}
+		Expect(form3Client.List(context.TODO(), list, nil)).To(Succeed())
+		Expect(list.Items).To(HaveLen(1))
+	})
+
+	It("should not serve resources of missing payments", func() {
+		submission := newSubmission("20dba636-7fac-4747-b27a-327ca12b9b27", "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5")
+
+		Expect(IsNotFound(form3Client.Create(context.TODO(), submission))).To(BeTrue())
+	})
+
+	It("should require the payment ID", func() {
+		err := form3Client.Fetch(context.TODO(), &api.PaymentRecall{})
+		Expect(err).To(MatchError("missing or invalid argument: uuid"))
+
+		recall := api.NewPaymentRecall("", "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5", 0)
+		Expect(form3Client.Fetch(context.TODO(), recall)).To(MatchError("missing parent api.Payment of api.PaymentRecall"))
+	})
+})
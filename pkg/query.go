@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+// DateLayout is the format of the bounds of date range filters.
+const DateLayout = "2006-01-02"
+
+var ErrUnknownFilter = errors.New("unknown filter")
+
+type dateRange struct {
+	from, to time.Time
+}
+
+// Filter narrows a List down to the resources matching every filter. Names
+// are checked against the filters registered for the listed type in
+// api.Schema:
+//
+//	filter := NewFilter().Where("bank_id", "400300", "400302").Where("country", "GB")
+type Filter struct {
+	values map[string][]string
+	ranges map[string]dateRange
+}
+
+func NewFilter() *Filter {
+	return &Filter{
+		values: map[string][]string{},
+		ranges: map[string]dateRange{},
+	}
+}
+
+// Where matches resources whose field equals one of values.
+func (f *Filter) Where(name string, values ...string) *Filter {
+	if f.values == nil {
+		f.values = map[string][]string{}
+	}
+
+	f.values[name] = append(f.values[name], values...)
+
+	return f
+}
+
+// Between matches resources whose date field falls between from and to,
+// both included. A zero bound leaves the range open on that side.
+func (f *Filter) Between(name string, from, to time.Time) *Filter {
+	if f.ranges == nil {
+		f.ranges = map[string]dateRange{}
+	}
+
+	f.ranges[name] = dateRange{from: from, to: to}
+
+	return f
+}
+
+// Names returns the names of the filters in use, sorted.
+func (f *Filter) Names() []string {
+	names := make([]string, 0, len(f.values)+len(f.ranges))
+
+	for name := range f.values {
+		names = append(names, name)
+	}
+
+	for name := range f.ranges {
+		if _, exists := f.values[name]; !exists {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// encode adds the filters to query, for resources like obj. Multiple values
+// of a filter are sent comma separated, as the API expects.
+func (f *Filter) encode(obj api.Object, query url.Values) error {
+	for _, name := range f.Names() {
+		if !api.Schema.HasFilter(obj, name) {
+			return fmt.Errorf("%w %s for %s", ErrUnknownFilter, name, api.Schema.TypeName(obj))
+		}
+	}
+
+	for name, values := range f.values {
+		query.Set(fmt.Sprintf("filter[%s]", name), strings.Join(values, ","))
+	}
+
+	for name, bounds := range f.ranges {
+		if !bounds.from.IsZero() {
+			query.Set(fmt.Sprintf("filter[%s_from]", name), bounds.from.Format(DateLayout))
+		}
+
+		if !bounds.to.IsZero() {
+			query.Set(fmt.Sprintf("filter[%s_to]", name), bounds.to.Format(DateLayout))
+		}
+	}
+
+	return nil
+}
+
+// Values returns the query listing resources like obj with these options.
+func (l *ListOptions) Values(obj api.Object) (url.Values, error) {
+	query := url.Values{}
+
+	if l.PageNumber != 0 {
+		query.Set("page[number]", strconv.Itoa(l.PageNumber))
+	}
+
+	if l.PageSize != 0 {
+		query.Set("page[size]", strconv.Itoa(l.PageSize))
+	}
+
+	if l.Filter != nil {
+		if err := l.Filter.encode(obj, query); err != nil {
+			return nil, err
+		}
+	}
+
+	return query, nil
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("ListOptions", func() {
+	It("should encode pages and filters", func() {
+		options := &ListOptions{
+			PageNumber: 2,
+			PageSize:   10,
+			Filter: NewFilter().
+				Where("bank_id", "400300", "400302").
+				Where("customer_id", "234").
+				Where("country", "GB").
+				Where("status", "confirmed"),
+		}
+
+		query, err := options.Values(&api.AccountList{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(query).To(Equal(url.Values{
+			"page[number]":        {"2"},
+			"page[size]":          {"10"},
+			"filter[bank_id]":     {"400300,400302"},
+			"filter[customer_id]": {"234"},
+			"filter[country]":     {"GB"},
+			"filter[status]":      {"confirmed"},
+		}))
+	})
+
+	It("should escape values", func() {
+		options := &ListOptions{Filter: NewFilter().Where("reference", "Em's piano & guitar")}
+
+		query, err := options.Values(&api.PaymentList{})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(query.Encode()).To(Equal("filter%5Breference%5D=Em%27s+piano+%26+guitar"))
+	})
+
+	It("should encode date ranges", func() {
+		from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2021, 1, 31, 0, 0, 0, 0, time.UTC)
+
+		query, err := (&ListOptions{Filter: NewFilter().Between("processing_date", from, to)}).Values(&api.PaymentList{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(query).To(Equal(url.Values{
+			"filter[processing_date_from]": {"2021-01-01"},
+			"filter[processing_date_to]":   {"2021-01-31"},
+		}))
+
+		query, err = (&ListOptions{Filter: NewFilter().Between("processing_date", from, time.Time{})}).Values(&api.PaymentList{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(query).To(Equal(url.Values{"filter[processing_date_from]": {"2021-01-01"}}))
+	})
+
+	It("should build filters from the zero value", func() {
+		var filter Filter
+		filter.Where("country", "GB")
+
+		query, err := (&ListOptions{Filter: &filter}).Values(&api.AccountList{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(query).To(Equal(url.Values{"filter[country]": {"GB"}}))
+
+		from := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		query, err = (&ListOptions{Filter: (&Filter{}).Between("processing_date", from, time.Time{})}).Values(&api.PaymentList{})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(query).To(Equal(url.Values{"filter[processing_date_from]": {"2021-01-01"}}))
+	})
+
+	It("should reject filters the listed type doesn't offer", func() {
+		_, err := (&ListOptions{Filter: NewFilter().Where("currency", "GBP")}).Values(&api.AccountList{})
+
+		Expect(errors.Is(err, ErrUnknownFilter)).To(BeTrue())
+		Expect(err).To(MatchError("unknown filter currency for api.AccountList"))
+	})
+
+	It("should send the query when listing", func() {
+		var query url.Values
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			_, _ = w.Write([]byte(`{"data": [], "links": {}}`))
+		}))
+		defer server.Close()
+
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		err := form3Client.List(context.TODO(), &api.AccountList{}, &ListOptions{
+			PageSize: 5,
+			Filter:   NewFilter().Where("iban", "GB82WEST12345698765432"),
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(query.Get("page[size]")).To(Equal("5"))
+		Expect(query.Get("filter[iban]")).To(Equal("GB82WEST12345698765432"))
+
+		err = form3Client.List(context.TODO(), &api.AccountList{}, &ListOptions{Filter: NewFilter().Where("amount", "1")})
+		Expect(errors.Is(err, ErrUnknownFilter)).To(BeTrue())
+	})
+})
@@ -0,0 +1,173 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+
+// TokenBucket allows Rate requests per second on average, and bursts of up
+// to Burst requests. A Rate of 0 or less doesn't limit requests.
+type TokenBucket struct {
+	Rate  float64
+	Burst int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket returns a full bucket.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		Rate:   rate,
+		Burst:  burst,
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it. The
+// token count goes negative while requests are waiting for their turn.
+func (b *TokenBucket) reserve() time.Duration {
+	if b.Rate <= 0 {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.now != nil {
+		now = b.now()
+	}
+
+	// Buckets start full, including the ones built as literals.
+	if b.last.IsZero() {
+		b.tokens = float64(b.Burst)
+	} else {
+		b.tokens = math.Min(float64(b.Burst), b.tokens+now.Sub(b.last).Seconds()*b.Rate)
+	}
+
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.Rate * float64(time.Second))
+}
+
+// cancel gives back a token taken by reserve but never used.
+func (b *TokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(float64(b.Burst), b.tokens+1)
+}
+
+// Wait blocks until a token is available. It fails right away if ctx ends
+// before then.
+func (b *TokenBucket) Wait(ctx context.Context) (time.Duration, error) {
+	wait := b.reserve()
+	if wait == 0 {
+		return 0, nil
+	}
+
+	if !fitsDeadline(ctx, wait) {
+		b.cancel()
+		return 0, fmt.Errorf("%w: would wait %s past the deadline", ErrRateLimitExceeded, wait)
+	}
+
+	if !sleep(ctx, wait) {
+		b.cancel()
+		return 0, ctx.Err()
+	}
+
+	return wait, nil
+}
+
+// RateLimitStats sums up the time requests spent waiting for the limiter.
+type RateLimitStats struct {
+	Requests int64
+	Delayed  int64
+	Waited   time.Duration
+}
+
+// RateLimiter keeps requests under a rate. Requests take a token from the
+// bucket of their HTTP method, if there is one, and then from Default.
+type RateLimiter struct {
+	Default *TokenBucket
+	Verbs   map[string]*TokenBucket
+	// OnWait is called after each request delayed by the limiter.
+	OnWait func(method string, wait time.Duration)
+
+	mu    sync.Mutex
+	stats RateLimitStats
+}
+
+// NewRateLimiter allows rate requests per second, with bursts of burst
+// requests. Per verb limits can be added to Verbs.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		Default: NewTokenBucket(rate, burst),
+		Verbs:   map[string]*TokenBucket{},
+	}
+}
+
+// Wait blocks until a request with the given method is allowed.
+func (l *RateLimiter) Wait(ctx context.Context, method string) error {
+	var (
+		waited time.Duration
+		taken  []*TokenBucket
+	)
+
+	for _, bucket := range []*TokenBucket{l.Verbs[method], l.Default} {
+		if bucket == nil {
+			continue
+		}
+
+		wait, err := bucket.Wait(ctx)
+		if err != nil {
+			// The request isn't sent, so the tokens taken from the other
+			// buckets are given back.
+			for _, previous := range taken {
+				previous.cancel()
+			}
+
+			return err
+		}
+
+		taken = append(taken, bucket)
+		waited += wait
+	}
+
+	l.mu.Lock()
+	l.stats.Requests++
+
+	if waited > 0 {
+		l.stats.Delayed++
+		l.stats.Waited += waited
+	}
+	l.mu.Unlock()
+
+	if waited > 0 && l.OnWait != nil {
+		l.OnWait(method, waited)
+	}
+
+	return nil
+}
+
+func (l *RateLimiter) Stats() RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
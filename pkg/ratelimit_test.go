@@ -0,0 +1,167 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("TokenBucket", func() {
+	var (
+		bucket *TokenBucket
+		now    time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		bucket = NewTokenBucket(10, 2)
+		bucket.now = func() time.Time { return now }
+	})
+
+	It("should allow bursts without waiting", func() {
+		Expect(bucket.reserve()).To(BeZero())
+		Expect(bucket.reserve()).To(BeZero())
+		Expect(bucket.reserve()).To(Equal(100 * time.Millisecond))
+		Expect(bucket.reserve()).To(Equal(200 * time.Millisecond))
+	})
+
+	It("should refill up to the burst", func() {
+		Expect(bucket.reserve()).To(BeZero())
+		Expect(bucket.reserve()).To(BeZero())
+
+		now = now.Add(time.Hour)
+
+		Expect(bucket.reserve()).To(BeZero())
+		Expect(bucket.reserve()).To(BeZero())
+		Expect(bucket.reserve()).To(Equal(100 * time.Millisecond))
+	})
+
+	It("should fail without waiting when the deadline is too close", func() {
+		bucket.reserve()
+		bucket.reserve()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := bucket.Wait(ctx)
+		Expect(errors.Is(err, ErrRateLimitExceeded)).To(BeTrue())
+
+		// The token is given back.
+		Expect(bucket.reserve()).To(Equal(100 * time.Millisecond))
+	})
+
+	It("should stop waiting when the context is cancelled", func() {
+		bucket.reserve()
+		bucket.reserve()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := bucket.Wait(ctx)
+		Expect(err).To(Equal(context.Canceled))
+	})
+
+	It("should start full when built as a literal", func() {
+		literal := &TokenBucket{Rate: 1, Burst: 1}
+
+		Expect(literal.Wait(context.TODO())).To(BeZero())
+		Expect(literal.reserve()).To(BeNumerically(">", 900*time.Millisecond))
+	})
+
+	It("should not limit without a positive rate", func() {
+		for _, unlimited := range []*TokenBucket{{}, NewTokenBucket(0, 1), NewTokenBucket(-1, 1)} {
+			for i := 0; i < 3; i++ {
+				Expect(unlimited.Wait(context.TODO())).To(BeZero())
+			}
+		}
+	})
+
+	It("should give back the verb token when the default bucket fails", func() {
+		limiter := &RateLimiter{Default: bucket, Verbs: map[string]*TokenBucket{http.MethodPost: NewTokenBucket(10, 1)}}
+		limiter.Verbs[http.MethodPost].now = bucket.now
+
+		bucket.reserve()
+		bucket.reserve()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(limiter.Wait(ctx, http.MethodPost)).To(Equal(context.Canceled))
+		Expect(limiter.Verbs[http.MethodPost].reserve()).To(BeZero())
+		Expect(limiter.Stats().Requests).To(BeZero())
+	})
+})
+
+var _ = Describe("RateLimiter", func() {
+	var (
+		server   *httptest.Server
+		requests int32
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&requests, 0)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			_, _ = w.Write([]byte(`{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "version": 0}}`))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should delay requests over the rate", func() {
+		var waits []time.Duration
+
+		limiter := NewRateLimiter(50, 1)
+		limiter.OnWait = func(method string, wait time.Duration) {
+			Expect(method).To(Equal(http.MethodGet))
+			waits = append(waits, wait)
+		}
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithRateLimiter(limiter))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		start := time.Now()
+
+		for i := 0; i < 3; i++ {
+			Expect(form3Client.Fetch(context.TODO(), account)).To(Succeed())
+		}
+
+		Expect(time.Since(start)).To(BeNumerically(">=", 30*time.Millisecond))
+		Expect(waits).To(HaveLen(2))
+
+		stats := limiter.Stats()
+		Expect(stats.Requests).To(Equal(int64(3)))
+		Expect(stats.Delayed).To(Equal(int64(2)))
+		Expect(stats.Waited).To(BeNumerically(">=", 30*time.Millisecond))
+	})
+
+	It("should apply per verb limits on top of the default one", func() {
+		limiter := NewRateLimiter(1000, 1000)
+		limiter.Verbs[http.MethodDelete] = NewTokenBucket(0.001, 1)
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithRateLimiter(limiter))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		Expect(form3Client.Delete(ctx, account)).To(Succeed())
+
+		err := form3Client.Delete(ctx, account)
+		Expect(errors.Is(err, ErrRateLimitExceeded)).To(BeTrue())
+		Expect(form3Client.Fetch(ctx, account)).To(Succeed())
+
+		Expect(atomic.LoadInt32(&requests)).To(Equal(int32(2)))
+	})
+})
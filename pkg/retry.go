@@ -0,0 +1,267 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy describes how idempotent requests are retried when upstream
+// fails with a transient error.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the computed wait between attempts.
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after every attempt.
+	Multiplier float64
+	// Jitter is the fraction of the backoff, in [0, 1], that is randomised.
+	Jitter float64
+	// RetryableStatuses are the response codes that trigger a retry.
+	RetryableStatuses map[int]bool
+	// OnRetry, if set, is called before waiting for the next attempt.
+	OnRetry func(attempt int, wait time.Duration, resp *http.Response, err error)
+	// OnDone, if set, is called once a request is done with the number of
+	// attempts made, whether it succeeded or not. resp mustn't be read.
+	OnDone func(attempts int, resp *http.Response, err error)
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatuses: map[int]bool{
+			http.StatusTooManyRequests:     true,
+			http.StatusInternalServerError: true,
+			http.StatusBadGateway:          true,
+			http.StatusServiceUnavailable:  true,
+			http.StatusGatewayTimeout:      true,
+		},
+	}
+}
+
+// RetryError is returned when all the attempts allowed by a RetryPolicy failed.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("giving up after %d attempts: %s", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// Attempts returns how many attempts were made for a request which failed,
+// either after giving up or with a response which isn't retried, or 1 if err
+// doesn't carry retry information.
+func Attempts(err error) int {
+	var retryErr *RetryError
+	if errors.As(err, &retryErr) {
+		return retryErr.Attempts
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Attempts > 0 {
+		return apiErr.Attempts
+	}
+
+	return 1
+}
+
+type attemptKey struct{}
+
+// attemptOf returns the attempt which got resp, as set by doWithRetry.
+func attemptOf(resp *http.Response) int {
+	if resp.Request != nil {
+		if attempt, ok := resp.Request.Context().Value(attemptKey{}).(int); ok {
+			return attempt
+		}
+	}
+
+	return 1
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodPut:
+		return true
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return isTransientErr(err)
+	}
+
+	return p.RetryableStatuses[resp.StatusCode]
+}
+
+func isTransientErr(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	wait := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		wait -= wait * p.Jitter * rand.Float64() // nolint: gosec
+	}
+
+	return time.Duration(wait)
+}
+
+// retryAfter parses the Retry-After header, which holds either a number of
+// seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func (p *RetryPolicy) wait(attempt int, resp *http.Response) time.Duration {
+	if wait, ok := retryAfter(resp); ok {
+		return wait
+	}
+
+	return p.backoff(attempt)
+}
+
+// fitsDeadline reports whether waiting for the given duration would still
+// leave the context deadline in the future.
+func fitsDeadline(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+
+	return !ok || time.Until(deadline) > wait
+}
+
+func sleep(ctx context.Context, wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (c *Form3Client) doWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
+	resp, attempts, err := c.retry(ctx, req)
+
+	if c.RetryPolicy.OnDone != nil {
+		c.RetryPolicy.OnDone(attempts, resp, err)
+	}
+
+	return resp, err
+}
+
+// retry sends req until it succeeds, fails with an error which isn't
+// transient or the policy gives up, and returns the number of attempts.
+func (c *Form3Client) retry(ctx context.Context, req *http.Request) (*http.Response, int, error) {
+	policy := c.RetryPolicy
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			// The attempt is kept in the context for errors built from the
+			// response.
+			attemptReq = req.Clone(context.WithValue(ctx, attemptKey{}, attempt))
+
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, attempt, err
+				}
+
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := c.do(attemptReq)
+		if !policy.shouldRetry(ctx, resp, err) {
+			if err != nil && attempt > 1 {
+				return nil, attempt, &RetryError{Attempts: attempt, Err: err}
+			}
+
+			return resp, attempt, err
+		}
+
+		wait := policy.wait(attempt, resp)
+
+		if attempt >= policy.MaxAttempts || !fitsDeadline(ctx, wait) {
+			if err == nil {
+				err = c.err(resp)
+				resp.Body.Close()
+			}
+
+			return nil, attempt, &RetryError{Attempts: attempt, Err: err}
+		}
+
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, wait, resp, err)
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if !sleep(ctx, wait) {
+			return nil, attempt, &RetryError{Attempts: attempt, Err: ctx.Err()}
+		}
+	}
+}
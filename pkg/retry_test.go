@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("RetryPolicy", func() {
+	var (
+		server   *httptest.Server
+		requests int32
+		statuses []int
+	)
+
+	policy := func() *RetryPolicy {
+		policy := DefaultRetryPolicy()
+		policy.InitialBackoff = time.Millisecond
+		policy.MaxBackoff = 10 * time.Millisecond
+
+		return policy
+	}
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&requests, 0)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := int(atomic.AddInt32(&requests, 1)) - 1
+			if current < len(statuses) {
+				w.WriteHeader(statuses[current])
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"data": {"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "version": 0}}`))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should retry transient failures on fetch", func() {
+		statuses = []int{http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+		var retries []int
+		attempts := 0
+		retryPolicy := policy()
+		retryPolicy.OnRetry = func(attempt int, _ time.Duration, _ *http.Response, _ error) {
+			retries = append(retries, attempt)
+		}
+		retryPolicy.OnDone = func(done int, _ *http.Response, _ error) {
+			attempts = done
+		}
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(retryPolicy))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Fetch(context.TODO(), account)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(3))
+		Expect(retries).To(Equal([]int{1, 2}))
+		Expect(attempts).To(Equal(3))
+	})
+
+	It("should give up after the maximum number of attempts", func() {
+		statuses = []int{
+			http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway,
+		}
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy()))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Fetch(context.TODO(), account)
+		Expect(err).Should(HaveOccurred())
+
+		Expect(Attempts(err)).To(Equal(4))
+		Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(4))
+	})
+
+	It("should not retry non idempotent requests", func() {
+		statuses = []int{http.StatusServiceUnavailable}
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy()))
+		account := newGBAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Create(context.TODO(), account)
+		Expect(err).Should(HaveOccurred())
+
+		Expect(Attempts(err)).To(Equal(1))
+		Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(1))
+	})
+
+	It("should not retry client errors", func() {
+		statuses = []int{http.StatusNotFound}
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy()))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Delete(context.TODO(), account)
+		Expect(err).Should(HaveOccurred())
+
+		Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(1))
+	})
+
+	It("should report the attempts made whatever the outcome", func() {
+		statuses = []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusNotFound}
+
+		var done []int
+		retryPolicy := policy()
+		retryPolicy.OnDone = func(attempts int, _ *http.Response, _ error) {
+			done = append(done, attempts)
+		}
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(retryPolicy))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Fetch(context.TODO(), account)
+		Expect(IsNotFound(err)).To(BeTrue())
+		Expect(Attempts(err)).To(Equal(3))
+
+		Expect(form3Client.Fetch(context.TODO(), account)).To(Succeed())
+		Expect(done).To(Equal([]int{3, 1}))
+	})
+
+	It("should stop when Retry-After exceeds the context deadline", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy()))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Fetch(ctx, account)
+		Expect(err).Should(HaveOccurred())
+
+		Expect(Attempts(err)).To(Equal(1))
+		Expect(atomic.LoadInt32(&requests)).To(BeEquivalentTo(1))
+	})
+
+	It("should parse Retry-After in seconds", func() {
+		wait, ok := retryAfter(&http.Response{Header: http.Header{"Retry-After": []string{"2"}}})
+		Expect(ok).To(BeTrue())
+		Expect(wait).To(Equal(2 * time.Second))
+	})
+})
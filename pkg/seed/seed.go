@@ -0,0 +1,294 @@
+// Package seed resets the Form3 API to a known set of fixtures: it deletes
+// the existing resources of the fixture types, at their current version, and
+// creates the fixtures. It backs form3ctl seed and can be used from test
+// suites:
+//
+//	var _ = BeforeSuite(func() {
+//		_, err := seed.Run(context.TODO(), pkg.NewClient(pkg.WithBaseURL(host)), "../fixtures")
+//		Expect(err).NotTo(HaveOccurred())
+//	})
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var ErrSeedFailed = errors.New("seed failed")
+
+// Actions of failures.
+const (
+	ActionList   = "list"
+	ActionDelete = "delete"
+	ActionCreate = "create"
+)
+
+// KindOf returns the kind a fixture is named after, e.g. api.Account for
+// fetch_api.Account_personal.json.
+func KindOf(path string) (string, bool) {
+	name := filepath.Base(path)
+
+	for _, kind := range api.Schema.Kinds() {
+		if strings.Contains(name, fmt.Sprintf("_%s_", kind)) {
+			return kind, true
+		}
+	}
+
+	return "", false
+}
+
+// Load decodes the JSON fixtures under root of the given kinds, or of every
+// kind if none is given, sorted by path. Files not named after a kind are
+// skipped.
+func Load(root string, kinds ...string) ([]api.Object, error) {
+	wanted := map[string]bool{}
+	for _, kind := range kinds {
+		wanted[kind] = true
+	}
+
+	var objs []api.Object
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+
+		kind, ok := KindOf(path)
+		if !ok || (len(wanted) > 0 && !wanted[kind]) {
+			return nil
+		}
+
+		obj, err := loadFixture(path, kind)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		objs = append(objs, obj)
+
+		return nil
+	})
+
+	return objs, err
+}
+
+func loadFixture(path, kind string) (api.Object, error) {
+	dataObj, err := api.Schema.NewDataObj(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, dataObj); err != nil {
+		return nil, err
+	}
+
+	return dataObj.Data, nil
+}
+
+// Failure is a list, delete or create which failed. ID is empty for lists.
+type Failure struct {
+	Action string
+	Kind   string
+	ID     string
+	Err    error
+}
+
+func (f *Failure) Error() string {
+	if f.ID == "" {
+		return fmt.Sprintf("%s %s: %s", f.Action, f.Kind, f.Err)
+	}
+
+	return fmt.Sprintf("%s %s %s: %s", f.Action, f.Kind, f.ID, f.Err)
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+// Report lists what a seed did.
+type Report struct {
+	Deleted  []api.Object
+	Created  []api.Object
+	Failures []*Failure
+}
+
+func (r *Report) fail(action string, obj api.Object, err error) {
+	failure := &Failure{Action: action, Kind: api.Schema.TypeName(obj), ID: obj.GetID(), Err: err}
+	r.Failures = append(r.Failures, failure)
+}
+
+// Err returns ErrSeedFailed with the failures, if any.
+func (r *Report) Err() error {
+	if len(r.Failures) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(r.Failures))
+	for _, failure := range r.Failures {
+		messages = append(messages, failure.Error())
+	}
+
+	return fmt.Errorf("%w: %s", ErrSeedFailed, strings.Join(messages, "; "))
+}
+
+// Print writes a line per deleted, created or failed object.
+func (r *Report) Print(w io.Writer) {
+	for _, obj := range r.Deleted {
+		fmt.Fprintf(w, "deleted %s %s\n", api.Schema.TypeName(obj), obj.GetID())
+	}
+
+	for _, obj := range r.Created {
+		fmt.Fprintf(w, "created %s %s\n", api.Schema.TypeName(obj), obj.GetID())
+	}
+
+	for _, failure := range r.Failures {
+		fmt.Fprintf(w, "failed to %s\n", failure)
+	}
+}
+
+// lists returns the lists holding objs, e.g. one AccountList for accounts, and
+// one list per parent for nested resources. Nested lists come first, so
+// children are deleted before their parent.
+func lists(objs []api.Object) ([]api.Object, error) {
+	var result []api.Object
+
+	seen := map[string]bool{}
+
+	for _, obj := range objs {
+		kind := api.Schema.TypeName(obj) + "List"
+
+		parentID := ""
+		if child, ok := obj.(api.Child); ok {
+			parentID = child.GetParentID()
+		}
+
+		if seen[kind+"/"+parentID] {
+			continue
+		}
+
+		seen[kind+"/"+parentID] = true
+
+		list, err := api.Schema.NewObj(kind)
+		if err != nil {
+			return nil, err
+		}
+
+		if setter, ok := list.(api.ParentSetter); ok {
+			setter.SetParentID(parentID)
+		}
+
+		result = append(result, list)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		_, iNested := api.Schema.ParentOf(result[i])
+		_, jNested := api.Schema.ParentOf(result[j])
+
+		return iNested && !jNested
+	})
+
+	return result, nil
+}
+
+func items(list api.Object) []api.Object {
+	v, _ := api.EnforcePtr(list)
+	items := v.FieldByName("Items")
+
+	objs := make([]api.Object, 0, items.Len())
+	for i := 0; i < items.Len(); i++ {
+		objs = append(objs, items.Index(i).Addr().Interface().(api.Object))
+	}
+
+	return objs
+}
+
+// Clean deletes every resource held by the given lists, e.g.
+// &api.AccountList{}, at its current version.
+func Clean(ctx context.Context, client pkg.Client, lists ...api.Object) *Report {
+	report := &Report{}
+
+	for _, list := range lists {
+		if err := client.List(ctx, list, nil); err != nil {
+			report.Failures = append(report.Failures, &Failure{Action: ActionList, Kind: api.Schema.TypeName(list), Err: err})
+			continue
+		}
+
+		for _, obj := range items(list) {
+			if err := client.Delete(ctx, obj); err != nil {
+				report.fail(ActionDelete, obj, err)
+				continue
+			}
+
+			report.Deleted = append(report.Deleted, obj)
+		}
+	}
+
+	return report
+}
+
+// Create creates objs, parents first, carrying on after failures.
+func Create(ctx context.Context, client pkg.Client, objs []api.Object) *Report {
+	report := &Report{}
+
+	ordered := make([]api.Object, len(objs))
+	copy(ordered, objs)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		_, iNested := api.Schema.ParentOf(ordered[i])
+		_, jNested := api.Schema.ParentOf(ordered[j])
+
+		return !iNested && jNested
+	})
+
+	for _, obj := range ordered {
+		if err := client.Create(ctx, obj); err != nil {
+			report.fail(ActionCreate, obj, err)
+			continue
+		}
+
+		report.Created = append(report.Created, obj)
+	}
+
+	return report
+}
+
+// Run loads the fixtures under root, deletes the existing resources of their
+// types and creates the fixtures. The returned error wraps ErrSeedFailed if
+// anything failed, as detailed by the report.
+func Run(ctx context.Context, client pkg.Client, root string) (*Report, error) {
+	objs, err := Load(root)
+	if err != nil {
+		return &Report{}, err
+	}
+
+	fixtureLists, err := lists(objs)
+	if err != nil {
+		return &Report{}, err
+	}
+
+	report := Clean(ctx, client, fixtureLists...)
+	if len(report.Failures) > 0 {
+		return report, report.Err()
+	}
+
+	created := Create(ctx, client, objs)
+	report.Created = created.Created
+	report.Failures = created.Failures
+
+	return report, report.Err()
+}
@@ -0,0 +1,128 @@
+package seed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg"
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fake"
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+const fixtures = "../../fixtures"
+
+var _ = Describe("seed", func() {
+	It("should name fixtures after their kind", func() {
+		kind, ok := KindOf("fixtures/fetch_api.Account_personal.json")
+		Expect(ok).To(BeTrue())
+		Expect(kind).To(Equal("api.Account"))
+
+		kind, ok = KindOf("fetch_api.PaymentSubmission_accepted.json")
+		Expect(ok).To(BeTrue())
+		Expect(kind).To(Equal("api.PaymentSubmission"))
+
+		_, ok = KindOf("fixtures/README.json")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should load fixtures", func() {
+		objs, err := Load(fixtures)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(HaveLen(3))
+		Expect(objs[0]).To(BeAssignableToTypeOf(&api.Account{}))
+		Expect(objs[0].GetID()).NotTo(BeEmpty())
+
+		objs, err = Load(fixtures, "api.Payment")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objs).To(BeEmpty())
+	})
+
+	It("should report fixtures which can't be decoded", func() {
+		dir, err := ioutil.TempDir("", "fixtures")
+		Expect(err).NotTo(HaveOccurred())
+
+		defer os.RemoveAll(dir)
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "fetch_api.Account_broken.json"), []byte("{"), 0o600)).To(Succeed())
+
+		_, err = Load(dir)
+		Expect(err).To(MatchError(ContainSubstring("fetch_api.Account_broken.json: unexpected end of JSON input")))
+	})
+
+	It("should replace existing resources with the fixtures", func() {
+		server := fakeapi.NewServer()
+		defer server.Close()
+
+		existing := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+		existing.Type = "accounts"
+		existing.OrganisationID = "721763e9-b2e2-4ebb-8de9-b440e3cf23a6"
+		existing.Attributes = api.AccountAttributes{
+			Country: "GB", BankID: "400300", BankIDCode: "GBDSC", BIC: "NWBKGB22",
+			AccountClassification: api.AccountClassificationPersonal,
+		}
+		Expect(server.Seed(existing)).To(Succeed())
+
+		client := pkg.NewClient(pkg.WithBaseURL(server.URL))
+
+		// Updated resources are deleted at their new version.
+		existing.Attributes.CustomerID = "1234"
+		Expect(client.Update(context.TODO(), existing)).To(Succeed())
+
+		report, err := Run(context.TODO(), client, fixtures)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Deleted).To(HaveLen(1))
+		Expect(report.Created).To(HaveLen(3))
+
+		out := &bytes.Buffer{}
+		report.Print(out)
+		Expect(out.String()).To(HavePrefix("deleted api.Account ad27e265-9605-4b4b-a0e5-3003ea9cc4dc\ncreated api.Account "))
+
+		list := &api.AccountList{}
+		Expect(client.List(context.TODO(), list, nil)).To(Succeed())
+		Expect(list.Items).To(HaveLen(3))
+
+		report, err = Run(context.TODO(), client, fixtures)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Deleted).To(HaveLen(3))
+	})
+
+	It("should delete children first and create them last", func() {
+		const paymentID = "4ee3a8d8-ca7b-4290-a52c-dd5b6165ec43"
+
+		submission := api.NewPaymentSubmission(paymentID, "9e8a9ef8-7a5e-4b8b-a01f-56b2c7a56bc5", 0)
+		client := fake.NewClient()
+
+		fixtureLists, err := lists([]api.Object{submission, api.NewPayment(paymentID, 0)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fixtureLists).To(Equal([]api.Object{&api.PaymentSubmissionList{PaymentID: paymentID}, &api.PaymentList{}}))
+
+		report := Create(context.TODO(), client, []api.Object{submission, api.NewPayment(paymentID, 0)})
+		Expect(report.Failures).To(BeEmpty())
+		Expect(report.Created[0]).To(BeAssignableToTypeOf(&api.Payment{}))
+	})
+
+	It("should carry on and report failures", func() {
+		client := fake.NewClient()
+		client.PrependReactor(fake.VerbCreate, "api.Account", func(action fake.Action) (bool, error) {
+			if action.ID == "93bfaa94-9e48-402d-9744-6ef85c6303b0" {
+				return true, errors.New("boom")
+			}
+
+			return false, nil
+		})
+
+		report, err := Run(context.TODO(), client, fixtures)
+		Expect(errors.Is(err, ErrSeedFailed)).To(BeTrue())
+		Expect(err).To(MatchError("seed failed: create api.Account 93bfaa94-9e48-402d-9744-6ef85c6303b0: boom"))
+		Expect(report.Created).To(HaveLen(2))
+		Expect(report.Failures[0].Action).To(Equal(ActionCreate))
+	})
+})
@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/fakeapi"
+)
+
+var _ = Describe("subscriptions", func() {
+	var (
+		server      *fakeapi.Server
+		form3Client Client
+	)
+
+	BeforeEach(func() {
+		server = fakeapi.NewServer()
+		form3Client = NewClient(WithBaseURL(server.URL))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should subscribe to notifications", func() {
+		subscription := api.NewSubscription("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+		subscription.Type = "subscriptions"
+		subscription.OrganisationID = "721763e9-b2e2-4ebb-8de9-b440e3cf23a6"
+		subscription.Attributes = api.SubscriptionAttributes{
+			CallbackTransport: api.CallbackTransportHTTP,
+			CallbackURI:       "https://example.com/form3/callbacks",
+			RecordType:        "accounts",
+			EventType:         api.EventTypeCreated,
+		}
+
+		Expect(form3Client.Create(context.TODO(), subscription)).To(Succeed())
+
+		subscriptions, err := form3Client.Subscriptions().List(context.TODO(), nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(subscriptions).To(HaveLen(1))
+		Expect(subscriptions[0].Attributes).To(Equal(subscription.Attributes))
+
+		Expect(form3Client.Subscriptions().Delete(context.TODO(), subscription.ID, 0)).To(Succeed())
+	})
+
+	It("should re-activate subscriptions", func() {
+		deactivated, activated := true, false
+
+		subscription := api.NewSubscription("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+		subscription.Type = "subscriptions"
+		subscription.OrganisationID = "721763e9-b2e2-4ebb-8de9-b440e3cf23a6"
+		subscription.Attributes = api.SubscriptionAttributes{
+			CallbackTransport: api.CallbackTransportHTTP,
+			CallbackURI:       "https://example.com/form3/callbacks",
+			RecordType:        "accounts",
+			EventType:         api.EventTypeCreated,
+			Deactivated:       &deactivated,
+		}
+
+		Expect(form3Client.Create(context.TODO(), subscription)).To(Succeed())
+
+		subscription.Attributes.Deactivated = &activated
+		Expect(form3Client.Update(context.TODO(), subscription)).To(Succeed())
+
+		fetched, err := form3Client.Subscriptions().Get(context.TODO(), subscription.ID)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fetched.Attributes.Deactivated).To(Equal(&activated))
+	})
+})
@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Middleware wraps a RoundTripper in order to inspect or modify the requests
+// sent to upstream and the responses received.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+const (
+	defaultTimeout             = 30 * time.Second
+	defaultDialTimeout         = 10 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 100
+)
+
+// NewDefaultTransport returns the pooled transport used by DefaultHTTPClient.
+func NewDefaultTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   defaultDialTimeout,
+			KeepAlive: defaultKeepAlive,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          defaultMaxIdleConns,
+		MaxIdleConnsPerHost:   defaultMaxIdleConnsPerHost,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// DefaultHTTPClient is shared by every Form3Client that wasn't configured
+// with its own http.Client, so connections are reused across clients.
+var DefaultHTTPClient = &http.Client{
+	Timeout:   defaultTimeout,
+	Transport: NewDefaultTransport(),
+}
+
+func (c *Form3Client) buildHTTPClient() *http.Client {
+	base := c.HTTPClient
+	if base == nil {
+		base = DefaultHTTPClient
+	}
+
+	if c.Transport == nil && len(c.Middlewares) == 0 {
+		return base
+	}
+
+	transport := c.Transport
+	if transport == nil {
+		transport = base.Transport
+	}
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	// The first middleware is the outermost one, so it sees requests first.
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		transport = c.Middlewares[i](transport)
+	}
+
+	client := *base
+	client.Transport = transport
+
+	return &client
+}
+
+func (c *Form3Client) httpClient() *http.Client {
+	c.httpClientOnce.Do(func() {
+		c.client = c.buildHTTPClient()
+	})
+
+	return c.client
+}
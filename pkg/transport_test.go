@@ -0,0 +1,135 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/auth"
+)
+
+var _ = Describe("transport", func() {
+	respond := func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"data": {"version": 0}}`)),
+			Request:    req,
+		}, nil
+	}
+
+	It("should use the shared default client", func() {
+		form3Client := NewClient().(*Form3Client)
+
+		Expect(form3Client.httpClient()).To(BeIdenticalTo(DefaultHTTPClient))
+	})
+
+	It("should send requests through a custom transport", func() {
+		var urls []string
+
+		transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			urls = append(urls, req.URL.String())
+			return respond(req)
+		})
+
+		form3Client := NewClient(WithBaseURL("http://form3.test"), WithTransport(transport))
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Fetch(context.TODO(), account)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(urls).To(Equal([]string{"http://form3.test/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"}))
+		Expect(DefaultHTTPClient.Transport).NotTo(BeIdenticalTo(transport))
+	})
+
+	It("should run middleware in registration order", func() {
+		var calls []string
+
+		middleware := func(name string) Middleware {
+			return func(next http.RoundTripper) http.RoundTripper {
+				return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					calls = append(calls, name)
+					return next.RoundTrip(req)
+				})
+			}
+		}
+
+		form3Client := NewClient(
+			WithMiddleware(middleware("first"), middleware("second")),
+			WithTransport(RoundTripperFunc(respond)),
+		)
+		account := api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		err := form3Client.Fetch(context.TODO(), account)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(calls).To(Equal([]string{"first", "second"}))
+	})
+
+	It("should sign requests with the configured key", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		verifier := auth.NewVerifier("75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", &key.PublicKey)
+		server := httptest.NewServer(verifier.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"data": {"version": 0}}`))
+		})))
+		defer server.Close()
+
+		account := newGBAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0)
+
+		form3Client := NewClient(WithBaseURL(server.URL), WithSigningKey("75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8", key))
+		Expect(form3Client.Create(context.TODO(), account)).To(Succeed())
+
+		unsigned := NewClient(WithBaseURL(server.URL))
+		Expect(IsUnauthorized(unsigned.Create(context.TODO(), account))).To(BeTrue())
+	})
+
+	It("should authorize requests with the token source", func() {
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"access_token": "s3cr3t", "token_type": "bearer", "expires_in": 3600}`))
+		}))
+		defer tokenServer.Close()
+
+		var authorization string
+
+		transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			authorization = req.Header.Get("Authorization")
+			return respond(req)
+		})
+
+		form3Client := NewClient(
+			WithTokenSource(auth.NewClientCredentials(tokenServer.URL, "client", "secret")),
+			WithTransport(transport),
+		)
+
+		err := form3Client.Fetch(context.TODO(), api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(authorization).To(Equal("Bearer s3cr3t"))
+	})
+
+	It("should use an injected http client", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"data": {"version": 0}}`))
+		}))
+		defer server.Close()
+
+		httpClient := server.Client()
+		form3Client := NewClient(WithBaseURL(server.URL), WithHTTPClient(httpClient)).(*Form3Client)
+
+		err := form3Client.Fetch(context.TODO(), api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(form3Client.httpClient()).To(BeIdenticalTo(httpClient))
+	})
+})
@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"context"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+// TypedObject is satisfied by pointers to resources registered in api.Schema,
+// e.g. *api.Account.
+type TypedObject[T any] interface {
+	*T
+	api.Object
+	SetID(string)
+	SetVersion(int)
+}
+
+// TypedList is satisfied by pointers to list resources registered in
+// api.Schema, e.g. *api.AccountList.
+type TypedList[T any, L any] interface {
+	*L
+	api.Object
+	GetItems() []T
+}
+
+// TypedClient exposes the verbs of a Client for a single resource type, so
+// passing the wrong object is caught at compile time. It relies on the same
+// api.Schema registration as Client.
+type TypedClient[T any, L any, PT TypedObject[T], PL TypedList[T, L]] struct {
+	client Client
+}
+
+// AccountsClient is the TypedClient for api.Account resources.
+type AccountsClient = TypedClient[api.Account, api.AccountList, *api.Account, *api.AccountList]
+
+// PaymentsClient is the TypedClient for api.Payment resources.
+type PaymentsClient = TypedClient[api.Payment, api.PaymentList, *api.Payment, *api.PaymentList]
+
+// SubscriptionsClient is the TypedClient for api.Subscription resources.
+type SubscriptionsClient = TypedClient[api.Subscription, api.SubscriptionList, *api.Subscription, *api.SubscriptionList]
+
+// NewTypedClient wraps client for the resource T listed through L:
+//
+//	accounts := NewTypedClient[api.Account, api.AccountList](client)
+func NewTypedClient[T any, L any, PT TypedObject[T], PL TypedList[T, L]](client Client) *TypedClient[T, L, PT, PL] {
+	return &TypedClient[T, L, PT, PL]{client: client}
+}
+
+func (t *TypedClient[T, L, PT, PL]) Get(ctx context.Context, id string) (*T, error) {
+	obj := PT(new(T))
+	obj.SetID(id)
+
+	if err := t.client.Fetch(ctx, obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+func (t *TypedClient[T, L, PT, PL]) List(ctx context.Context, listOptions *ListOptions) ([]T, error) {
+	list := PL(new(L))
+
+	if err := t.client.List(ctx, list, listOptions); err != nil {
+		return nil, err
+	}
+
+	return list.GetItems(), nil
+}
+
+// Each calls fn for every item, fetching one page at a time. Returning an
+// error from fn stops the iteration and is returned as is.
+func (t *TypedClient[T, L, PT, PL]) Each(ctx context.Context, listOptions *ListOptions, fn func(*T) error) error {
+	iter := t.client.ListIter(PL(new(L)), listOptions)
+
+	for iter.Next(ctx) {
+		if err := fn(iter.Item().(PT)); err != nil {
+			return err
+		}
+	}
+
+	return iter.Err()
+}
+
+func (t *TypedClient[T, L, PT, PL]) Create(ctx context.Context, obj *T) error {
+	return t.client.Create(ctx, PT(obj))
+}
+
+func (t *TypedClient[T, L, PT, PL]) Update(ctx context.Context, obj *T) error {
+	return t.client.Update(ctx, PT(obj))
+}
+
+func (t *TypedClient[T, L, PT, PL]) Delete(ctx context.Context, id string, version int) error {
+	obj := PT(new(T))
+	obj.SetID(id)
+	obj.SetVersion(version)
+
+	return t.client.Delete(ctx, obj)
+}
@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("TypedClient", func() {
+	var (
+		server   *httptest.Server
+		accounts []api.Account
+		deleted  string
+	)
+
+	BeforeEach(func() {
+		accounts = []api.Account{
+			*api.NewAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 0),
+			*api.NewAccount("bd27e265-9605-4b4b-a0e5-3003ea9cc4dc", 2),
+		}
+		deleted = ""
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v1/organisation/accounts", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": accounts})
+		})
+		mux.HandleFunc("/v1/organisation/accounts/", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				deleted = r.URL.RequestURI()
+				w.WriteHeader(http.StatusNoContent)
+
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(api.WrapObject(&accounts[1]))
+		})
+
+		server = httptest.NewServer(mux)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should get accounts by id", func() {
+		account, err := NewClient(WithBaseURL(server.URL)).Accounts().Get(context.TODO(), accounts[1].ID)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(account).To(Equal(&accounts[1]))
+	})
+
+	It("should list accounts", func() {
+		items, err := NewClient(WithBaseURL(server.URL)).Accounts().List(context.TODO(), nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(items).To(Equal(accounts))
+	})
+
+	It("should iterate over accounts", func() {
+		var ids []string
+
+		err := NewClient(WithBaseURL(server.URL)).Accounts().Each(context.TODO(), nil, func(account *api.Account) error {
+			ids = append(ids, account.ID)
+			return nil
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(ids).To(Equal([]string{accounts[0].ID, accounts[1].ID}))
+	})
+
+	It("should delete accounts by id and version", func() {
+		err := NewClient(WithBaseURL(server.URL)).Accounts().Delete(context.TODO(), accounts[1].ID, 2)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(deleted).To(Equal("/v1/organisation/accounts/bd27e265-9605-4b4b-a0e5-3003ea9cc4dc?version=2"))
+	})
+
+	It("should be instantiable for any registered type", func() {
+		accountsClient := NewTypedClient[api.Account, api.AccountList](NewClient(WithBaseURL(server.URL)))
+
+		items, err := accountsClient.List(context.TODO(), nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(items).To(HaveLen(2))
+	})
+})
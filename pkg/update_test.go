@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+)
+
+var _ = Describe("update account", func() {
+	var (
+		server  *httptest.Server
+		stored  *api.Account
+		request *http.Request
+		sent    *api.Account
+	)
+
+	BeforeEach(func() {
+		stored = newGBAccount("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", 1)
+		stored.Attributes.Name = []string{"Samantha Holder"}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+
+			sent = &api.Account{}
+			if err := json.NewDecoder(r.Body).Decode(api.WrapObject(sent)); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if sent.Version != stored.Version {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"error_message": "invalid version"}`))
+				return
+			}
+
+			stored.Attributes = sent.Attributes
+			stored.Version++
+
+			_ = json.NewEncoder(w).Encode(api.WrapObject(stored))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should patch the account and write back the new version", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		account := newGBAccount(stored.ID, stored.Version)
+		account.Attributes.Name = []string{"Samantha Jones"}
+
+		err := form3Client.Update(context.TODO(), account)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(request.Method).To(Equal(http.MethodPatch))
+		Expect(request.URL.Path).To(Equal("/v1/organisation/accounts/ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"))
+		Expect(sent.Version).To(Equal(1))
+
+		Expect(account.Version).To(Equal(2))
+		Expect(account.Attributes.Name).To(Equal([]string{"Samantha Jones"}))
+	})
+
+	It("should return a version conflict for stale objects", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		account := newGBAccount(stored.ID, 0)
+
+		err := form3Client.Update(context.TODO(), account)
+		Expect(err).Should(HaveOccurred())
+
+		Expect(IsVersionConflict(err)).To(BeTrue())
+		Expect(IsConflict(err)).To(BeTrue())
+		Expect(errors.Is(err, ErrConflict)).To(BeTrue())
+		Expect(account.Version).To(Equal(0))
+	})
+
+	It("should return invalid request for missing uuid", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		err := form3Client.Update(context.TODO(), &api.Account{})
+		Expect(err).To(MatchError("missing or invalid argument: ID"))
+	})
+
+	It("should require a pointer", func() {
+		form3Client := NewClient(WithBaseURL(server.URL))
+
+		err := form3Client.Update(context.TODO(), *newGBAccount(stored.ID, 1))
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultRetention is how long notifications are remembered, longer than
+// Form3 keeps retrying a delivery.
+const DefaultRetention = 24 * time.Hour
+
+// Deduplicator remembers the IDs of handled notifications. Implementations
+// backed by a shared store let several receivers deduplicate together.
+type Deduplicator interface {
+	Seen(ctx context.Context, id string) (bool, error)
+	Mark(ctx context.Context, id string) error
+}
+
+// MemoryDeduplicator remembers notifications in memory for Retention.
+type MemoryDeduplicator struct {
+	Retention time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+	now  func() time.Time
+}
+
+func NewMemoryDeduplicator(retention time.Duration) *MemoryDeduplicator {
+	return &MemoryDeduplicator{
+		Retention: retention,
+		seen:      map[string]time.Time{},
+		now:       time.Now,
+	}
+}
+
+func (d *MemoryDeduplicator) Seen(ctx context.Context, id string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	markedAt, exists := d.seen[id]
+
+	return exists && d.now().Sub(markedAt) < d.Retention, nil
+}
+
+// Mark remembers id, and forgets the notifications older than Retention.
+func (d *MemoryDeduplicator) Mark(ctx context.Context, id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+
+	for seenID, markedAt := range d.seen {
+		if now.Sub(markedAt) >= d.Retention {
+			delete(d.seen, seenID)
+		}
+	}
+
+	d.seen[id] = now
+
+	return nil
+}
@@ -0,0 +1,300 @@
+// Package webhook receives the notifications Form3 sends to the callbacks of
+// subscriptions and dispatches them, as typed events, to registered handlers.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/auth"
+)
+
+// Any matches every event type when registering handlers.
+const Any = "*"
+
+var (
+	ErrUnknownRecordType = errors.New("unknown record type")
+	ErrMissingData       = errors.New("notification has no data")
+)
+
+// Notification is the body of a webhook callback, wrapping the resource the
+// event happened to.
+type Notification struct {
+	ID             string          `json:"id"`
+	OrganisationID string          `json:"organisation_id"`
+	EventType      string          `json:"event_type"`
+	RecordType     string          `json:"record_type"`
+	Version        int             `json:"version"`
+	Data           json.RawMessage `json:"data"`
+}
+
+// Event is a decoded notification. Object is a pointer to the resource, e.g.
+// *api.Account.
+type Event struct {
+	Notification *Notification
+	Kind         string
+	Object       api.Object
+}
+
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+type handler struct {
+	kind      string
+	eventType string
+	fn        HandlerFunc
+}
+
+// DefaultKinds maps the record types of notifications to the Scheme types
+// they're decoded into.
+var DefaultKinds = map[string]string{
+	"accounts":            "api.Account",
+	"payments":            "api.Payment",
+	"payment_submissions": "api.PaymentSubmission",
+	"returns":             "api.PaymentReturn",
+	"reversals":           "api.PaymentReversal",
+	"recalls":             "api.PaymentRecall",
+	"subscriptions":       "api.Subscription",
+}
+
+// Receiver is an http.Handler for webhook callbacks. Notifications are
+// delivered at least once, so the ones already handled are acknowledged
+// without reaching the handlers again.
+type Receiver struct {
+	// Verifier checks the signature of callbacks. Signatures aren't checked
+	// when nil.
+	Verifier *auth.Verifier
+	// Deduplicator remembers handled notifications, in memory if not set.
+	Deduplicator Deduplicator
+	// Kinds maps record types to Scheme types, DefaultKinds if not set.
+	Kinds map[string]string
+
+	mu       sync.RWMutex
+	handlers []*handler
+	inFlight map[string]bool
+	// memory remembers notifications when Deduplicator isn't set.
+	memory *MemoryDeduplicator
+}
+
+// NewReceiver returns a receiver checking signatures with verifier and
+// remembering notifications in memory.
+func NewReceiver(verifier *auth.Verifier) *Receiver {
+	kinds := make(map[string]string, len(DefaultKinds))
+	for recordType, kind := range DefaultKinds {
+		kinds[recordType] = kind
+	}
+
+	return &Receiver{
+		Verifier:     verifier,
+		Deduplicator: NewMemoryDeduplicator(DefaultRetention),
+		Kinds:        kinds,
+	}
+}
+
+// HandleFunc registers fn for events of the given Scheme kind, e.g.
+// "api.Account", and event type. Use Any to match every kind or event type.
+func (r *Receiver) HandleFunc(kind, eventType string, fn HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.handlers = append(r.handlers, &handler{kind: kind, eventType: eventType, fn: fn})
+}
+
+// Handle registers a typed handler for events on resources of type T:
+//
+//	webhook.Handle(receiver, api.EventTypeCreated, func(ctx context.Context, event *webhook.Event, account *api.Account) error {
+//		...
+//	})
+func Handle[T any, PT interface {
+	*T
+	api.Object
+}](r *Receiver, eventType string, fn func(context.Context, *Event, PT) error) {
+	kind := api.Schema.TypeName(PT(new(T)))
+
+	r.HandleFunc(kind, eventType, func(ctx context.Context, event *Event) error {
+		return fn(ctx, event, event.Object.(PT))
+	})
+}
+
+// Decode unmarshals the resource embedded in the notification into a new
+// object of the Scheme.
+func (r *Receiver) Decode(notification *Notification) (*Event, error) {
+	kinds := r.Kinds
+	if kinds == nil {
+		kinds = DefaultKinds
+	}
+
+	kind, exists := kinds[notification.RecordType]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRecordType, notification.RecordType)
+	}
+
+	if len(notification.Data) == 0 {
+		return nil, ErrMissingData
+	}
+
+	obj, err := api.Schema.NewObj(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(notification.Data, obj); err != nil {
+		return nil, err
+	}
+
+	return &Event{Notification: notification, Kind: kind, Object: obj}, nil
+}
+
+func (r *Receiver) matching(event *Event) []*handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var handlers []*handler
+
+	for _, h := range r.handlers {
+		if (h.kind == Any || h.kind == event.Kind) &&
+			(h.eventType == Any || h.eventType == event.Notification.EventType) {
+			handlers = append(handlers, h)
+		}
+	}
+
+	return handlers
+}
+
+// Dispatch runs the handlers of the event, stopping at the first error.
+func (r *Receiver) Dispatch(ctx context.Context, event *Event) error {
+	for _, h := range r.matching(event) {
+		if err := h.fn(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// claim marks the notification as being handled, so concurrent deliveries of
+// the same notification are retried later instead of handled twice.
+func (r *Receiver) claim(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.inFlight[id] {
+		return false
+	}
+
+	if r.inFlight == nil {
+		r.inFlight = map[string]bool{}
+	}
+
+	r.inFlight[id] = true
+
+	return true
+}
+
+// deduplicator returns the Deduplicator, or an in-memory one if not set.
+func (r *Receiver) deduplicator() Deduplicator {
+	if r.Deduplicator != nil {
+		return r.Deduplicator
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.memory == nil {
+		r.memory = NewMemoryDeduplicator(DefaultRetention)
+	}
+
+	return r.memory
+}
+
+func (r *Receiver) release(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.inFlight, id)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `{"error_message": %q}`, err.Error())
+}
+
+// ServeHTTP verifies, decodes and dispatches a callback. Any response other
+// than 200 makes Form3 deliver the notification again.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	if r.Verifier != nil {
+		if err := r.Verifier.Verify(req); err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+	}
+
+	notification := &Notification{}
+	if err := json.NewDecoder(req.Body).Decode(notification); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := r.handle(req.Context(), notification); err != nil {
+		status := http.StatusInternalServerError
+
+		switch {
+		case errors.Is(err, ErrUnknownRecordType), errors.Is(err, ErrMissingData):
+			status = http.StatusBadRequest
+		case errors.Is(err, errInFlight):
+			status = http.StatusServiceUnavailable
+		}
+
+		writeError(w, status, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+var errInFlight = errors.New("notification is already being handled")
+
+func (r *Receiver) handle(ctx context.Context, notification *Notification) error {
+	if notification.ID == "" {
+		return r.dispatch(ctx, notification)
+	}
+
+	if !r.claim(notification.ID) {
+		return errInFlight
+	}
+
+	defer r.release(notification.ID)
+
+	deduplicator := r.deduplicator()
+
+	seen, err := deduplicator.Seen(ctx, notification.ID)
+	if err != nil || seen {
+		return err
+	}
+
+	if err := r.dispatch(ctx, notification); err != nil {
+		return err
+	}
+
+	return deduplicator.Mark(ctx, notification.ID)
+}
+
+func (r *Receiver) dispatch(ctx context.Context, notification *Notification) error {
+	event, err := r.Decode(notification)
+	if err != nil {
+		return err
+	}
+
+	return r.Dispatch(ctx, event)
+}
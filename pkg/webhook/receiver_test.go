@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/vtemian/form3/pkg/api"
+	"github.com/vtemian/form3/pkg/auth"
+)
+
+const (
+	keyID = "75a8ba12-fff2-4a52-ad8a-e8b34c5ccec8"
+
+	accountCreated = `{
+		"id": "0c4e4e8f-6d7f-4e0a-8e4b-3c3f0a3e3b1a",
+		"organisation_id": "721763e9-b2e2-4ebb-8de9-b440e3cf23a6",
+		"event_type": "created",
+		"record_type": "accounts",
+		"version": 0,
+		"data": {
+			"type": "accounts",
+			"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+			"version": 0,
+			"organisation_id": "721763e9-b2e2-4ebb-8de9-b440e3cf23a6",
+			"attributes": {"country": "GB", "bank_id": "400300", "account_classification": "Personal"}
+		}
+	}`
+)
+
+var _ = Describe("Receiver", func() {
+	var (
+		key      *rsa.PrivateKey
+		receiver *Receiver
+		server   *httptest.Server
+		client   *http.Client
+	)
+
+	post := func(client *http.Client, body string) int {
+		resp, err := client.Post(server.URL, "application/json", bytes.NewBufferString(body))
+		Expect(err).ShouldNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		receiver = NewReceiver(auth.NewVerifier(keyID, &key.PublicKey))
+		server = httptest.NewServer(receiver)
+
+		client = &http.Client{Transport: auth.NewSigner(keyID, key).Transport(http.DefaultTransport)}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should dispatch typed events", func() {
+		var received []*api.Account
+
+		Handle(receiver, api.EventTypeCreated, func(ctx context.Context, event *Event, account *api.Account) error {
+			Expect(event.Notification.RecordType).To(Equal("accounts"))
+
+			received = append(received, account)
+
+			return nil
+		})
+
+		Handle(receiver, Any, func(ctx context.Context, event *Event, payment *api.Payment) error {
+			Fail("payments handler called for an account")
+			return nil
+		})
+
+		Expect(post(client, accountCreated)).To(Equal(http.StatusOK))
+
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].ID).To(Equal("ad27e265-9605-4b4b-a0e5-3003ea9cc4dc"))
+		Expect(received[0].Attributes.BankID).To(Equal("400300"))
+	})
+
+	It("should handle each notification once", func() {
+		calls := 0
+
+		receiver.HandleFunc(Any, Any, func(ctx context.Context, event *Event) error {
+			calls++
+			return nil
+		})
+
+		Expect(post(client, accountCreated)).To(Equal(http.StatusOK))
+		Expect(post(client, accountCreated)).To(Equal(http.StatusOK))
+
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should ask for a new delivery when a handler fails", func() {
+		failures := 1
+		calls := 0
+
+		receiver.HandleFunc("api.Account", api.EventTypeCreated, func(ctx context.Context, event *Event) error {
+			calls++
+
+			if failures > 0 {
+				failures--
+				return errors.New("database is down")
+			}
+
+			return nil
+		})
+
+		Expect(post(client, accountCreated)).To(Equal(http.StatusInternalServerError))
+		Expect(post(client, accountCreated)).To(Equal(http.StatusOK))
+		Expect(post(client, accountCreated)).To(Equal(http.StatusOK))
+
+		Expect(calls).To(Equal(2))
+	})
+
+	It("should reject callbacks that aren't signed", func() {
+		receiver.HandleFunc(Any, Any, func(ctx context.Context, event *Event) error {
+			Fail("handler called for an unsigned callback")
+			return nil
+		})
+
+		Expect(post(http.DefaultClient, accountCreated)).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should work when built as a literal", func() {
+		literal := &Receiver{}
+		server.Config.Handler = literal
+
+		calls := 0
+
+		literal.HandleFunc("api.Account", Any, func(ctx context.Context, event *Event) error {
+			calls++
+			return nil
+		})
+
+		Expect(post(http.DefaultClient, accountCreated)).To(Equal(http.StatusOK))
+		Expect(post(http.DefaultClient, accountCreated)).To(Equal(http.StatusOK))
+
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should reject unknown record types and malformed bodies", func() {
+		Expect(post(client, `{"id": "1", "record_type": "mandates", "data": {}}`)).To(Equal(http.StatusBadRequest))
+		Expect(post(client, `{"id": "2", "record_type": "accounts"}`)).To(Equal(http.StatusBadRequest))
+		Expect(post(client, `not json`)).To(Equal(http.StatusBadRequest))
+	})
+})
+
+var _ = Describe("MemoryDeduplicator", func() {
+	It("should forget notifications after the retention", func() {
+		deduplicator := NewMemoryDeduplicator(DefaultRetention)
+		now := deduplicator.now()
+		deduplicator.now = func() time.Time { return now }
+
+		Expect(deduplicator.Mark(context.TODO(), "1")).To(Succeed())
+		Expect(deduplicator.Seen(context.TODO(), "1")).To(BeTrue())
+		Expect(deduplicator.Seen(context.TODO(), "2")).To(BeFalse())
+
+		now = now.Add(DefaultRetention)
+		Expect(deduplicator.Seen(context.TODO(), "1")).To(BeFalse())
+
+		Expect(deduplicator.Mark(context.TODO(), "2")).To(Succeed())
+		Expect(deduplicator.seen).To(HaveLen(1))
+	})
+})